@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	antfarm "test/antFarm"
+)
+
+// analyze prints a farm's minimum vertex cut, its maximum number of parallel
+// paths and the rooms whose loss slows the ants down most
+func analyze(args []string) error {
+	flags := flag.NewFlagSet("analyze", flag.ExitOnError)
+	top := flags.Int("top", 10, "number of critical rooms to list")
+	maxAnts := flags.Int("max-ants", antfarm.DefaultMaxAnts, "largest number of ants accepted")
+	algo := flags.String("algo", antfarm.KShortestDisjoint.Name(), "path finder to rate rooms with: "+pathFinderNames())
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("Usage: go run . analyze [--top <n>] [--max-ants <n>] [--algo <name>] <filename>")
+	}
+
+	farm := antfarm.NewAntFarm()
+	farm.MaxAnts = *maxAnts
+	if err := farm.ParseInput(flags.Arg(0)); err != nil {
+		return err
+	}
+	finder, err := antfarm.LookupPathFinder(*algo)
+	if err != nil {
+		return err
+	}
+
+	analysis, err := farm.Analyze(antfarm.WithPathFinder(finder))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("max parallel paths: %d\n", analysis.MaxPaths)
+	if analysis.Cut == nil {
+		fmt.Printf("min vertex cut: none, %s and %s are linked directly\n", farm.Start.Name, farm.End.Name)
+	} else {
+		names := make([]string, len(analysis.Cut))
+		for i, room := range analysis.Cut {
+			names[i] = room.Name
+		}
+		fmt.Printf("min vertex cut: %s\n", strings.Join(names, " "))
+	}
+	fmt.Printf("turns for %d ants (%s): %d\n", farm.NumAnts, finder.Name(), analysis.Turns)
+
+	if len(analysis.Critical) == 0 {
+		return nil
+	}
+	fmt.Println("rooms that hurt most when lost:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  room\tpaths\tturns")
+	for i, impact := range analysis.Critical {
+		if i == *top {
+			break
+		}
+		turns := "no route"
+		if impact.Turns > 0 {
+			turns = fmt.Sprintf("%d (%+d)", impact.Turns, impact.Turns-analysis.Turns)
+		}
+		fmt.Fprintf(w, "  %s\t%d\t%s\n", impact.Room.Name, impact.MaxPaths, turns)
+	}
+	return w.Flush()
+}
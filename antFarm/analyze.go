@@ -0,0 +1,195 @@
+package antfarm
+
+import (
+	"errors"
+	"sort"
+
+	"test/models"
+)
+
+// Analysis describes how much traffic a farm can carry and where it is weakest
+type Analysis struct {
+	// MaxPaths is the most vertex-disjoint paths from start to end, which is
+	// how many ants can arrive per turn
+	MaxPaths int
+	// Cut is a smallest set of rooms separating start from end, sorted by
+	// name. It is nil when start and end are linked directly, since no rooms
+	// can separate them then.
+	Cut []*models.Room
+	// Turns is how long the ants need with every room in place
+	Turns int
+	// Critical rates losing each room on a maximum flow or on the chosen
+	// paths, those leaving the fewest paths first
+	Critical []RoomImpact
+}
+
+// RoomImpact is the effect of losing a single room
+type RoomImpact struct {
+	Room     *models.Room
+	MaxPaths int // disjoint paths left without the room
+	Turns    int // turns the ants need without the room, zero if none get through
+}
+
+// Analyze finds the farm's minimum vertex cut and rates how badly losing each
+// room slows the ants down. Every room on a maximum flow is rated, since
+// losing one takes a path off the farm's throughput even when the ants do
+// not use it, and so is every room the chosen paths send ants through.
+// Paths come from the path finder in opts, KShortestDisjoint by default.
+func (af *AntFarm) Analyze(opts ...Option) (Analysis, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	cfg, err := af.newRunConfig(append([]Option{WithPathFinder(KShortestDisjoint)}, opts...))
+	if err != nil {
+		return Analysis{}, err
+	}
+	if af.Start == nil || af.End == nil {
+		return Analysis{}, errors.New("farm has no start or end room")
+	}
+
+	sol, err := cfg.simulation(af).solve()
+	if err != nil {
+		return Analysis{}, err
+	}
+
+	p := newPlanner(cfg.ctx, af)
+	analysis := Analysis{MaxPaths: len(p.paths), Turns: sol.Turns}
+	if !hasRoom(af.Start.Connected, af.End) {
+		analysis.Cut = p.minCut(af)
+	}
+
+	rated := make(map[*models.Room]bool)
+	paths := append([]models.Path(nil), p.paths...)
+	for j, path := range sol.Paths {
+		if sol.Counts[j] > 0 {
+			paths = append(paths, path)
+		}
+	}
+	for _, path := range paths {
+		for _, room := range path.Rooms[1 : len(path.Rooms)-1] {
+			if rated[room] {
+				continue
+			}
+			rated[room] = true
+			clone := af.without(room)
+			impact := RoomImpact{Room: room, MaxPaths: len(newPlanner(cfg.ctx, clone).paths)}
+			if sol, err := cfg.simulation(clone).solve(); err == nil {
+				impact.Turns = sol.Turns
+			}
+			if err := cfg.ctx.Err(); err != nil {
+				return Analysis{}, err
+			}
+			analysis.Critical = append(analysis.Critical, impact)
+		}
+	}
+
+	// The fewest paths left is worst, then losing every route, then the
+	// slowest outcome
+	sort.SliceStable(analysis.Critical, func(i, j int) bool {
+		a, b := analysis.Critical[i], analysis.Critical[j]
+		if a.MaxPaths != b.MaxPaths {
+			return a.MaxPaths < b.MaxPaths
+		}
+		if (a.Turns == 0) != (b.Turns == 0) {
+			return a.Turns == 0
+		}
+		if a.Turns != b.Turns {
+			return a.Turns > b.Turns
+		}
+		return a.Room.Name < b.Room.Name
+	})
+	return analysis, nil
+}
+
+// minCut returns the rooms of a minimum vertex cut, given a saturated flow.
+// Tunnels are treated as unlimited so only rooms can be cut: the search from
+// the start crosses any tunnel forwards, enters a room's exit side only when
+// the room carries no flow, and may walk flow backwards. Rooms whose entry is
+// reached but whose exit is not form the cut closest to the start.
+func (p *planner) minCut(af *AntFarm) []*models.Room {
+	first := side{af.Start, true}
+	seen := map[side]bool{first: true}
+	queue := []side{first}
+	visit := func(s side) {
+		if !seen[s] {
+			seen[s] = true
+			queue = append(queue, s)
+		}
+	}
+
+	for len(queue) > 0 {
+		cur := queue[0]
+		queue = queue[1:]
+		room := cur.room
+
+		if cur.exit {
+			if room != af.Start && p.successor(room) != nil {
+				visit(side{room, false})
+			}
+			for _, next := range room.Connected {
+				if next != af.Start {
+					visit(side{next, false})
+				}
+			}
+			continue
+		}
+
+		if room == af.End {
+			continue
+		}
+		if pred := p.predecessor(room); pred != nil {
+			visit(side{pred, true})
+		} else {
+			visit(side{room, true})
+		}
+	}
+
+	cut := make([]*models.Room, 0)
+	for s := range seen {
+		if !s.exit && s.room != af.End && !seen[side{s.room, true}] {
+			cut = append(cut, s.room)
+		}
+	}
+	sort.Slice(cut, func(i, j int) bool {
+		return cut[i].Name < cut[j].Name
+	})
+	return cut
+}
+
+// copyFarm returns a copy of the farm's rooms and tunnels that shares nothing
+// with it, so the copy can be changed and searched freely
+func (af *AntFarm) copyFarm() *AntFarm {
+	return af.without(nil)
+}
+
+// without returns a copy of the farm, as copyFarm does, with one room and its
+// tunnels left out
+func (af *AntFarm) without(removed *models.Room) *AntFarm {
+	clone := &AntFarm{
+		NumAnts: af.NumAnts,
+		MaxAnts: af.MaxAnts,
+		Workers: af.Workers,
+		Limits:  af.Limits,
+		Rooms:   make(map[string]*models.Room, len(af.Rooms)),
+	}
+	for name, room := range af.Rooms {
+		if room != removed {
+			copied := *room
+			copied.Connected = make([]*models.Room, 0, len(room.Connected))
+			clone.Rooms[name] = &copied
+		}
+	}
+	for name, room := range af.Rooms {
+		if room == removed {
+			continue
+		}
+		for _, next := range room.Connected {
+			if next != removed {
+				clone.Rooms[name].Connected = append(clone.Rooms[name].Connected, clone.Rooms[next.Name])
+			}
+		}
+	}
+	clone.Start = clone.Rooms[af.Start.Name]
+	clone.End = clone.Rooms[af.End.Name]
+	return clone
+}
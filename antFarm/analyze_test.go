@@ -0,0 +1,180 @@
+package antfarm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// funnelFarm forces every path through the single room a
+const funnelFarm = `3
+##start
+s 0 0
+a 1 0
+b 2 0
+c 2 1
+##end
+t 3 0
+s-a
+a-b
+a-c
+b-t
+c-t
+`
+
+// detourFarm sends its one ant along s-a-t, while the detour s-b-c-d-t
+// carries the second path of the maximum flow
+const detourFarm = `1
+##start
+s 0 0
+a 1 0
+b 1 1
+c 2 1
+d 3 1
+##end
+t 4 0
+s-a
+a-t
+s-b
+b-c
+c-d
+d-t
+`
+
+// bypassFarm sends its one ant along s-a-p-t. Losing a slows it down but
+// leaves both paths through the bypass x, while losing a room on s-c-d-e-q-t
+// leaves just one.
+const bypassFarm = `1
+##start
+s 0 0
+a 1 0
+p 2 0
+c 1 1
+d 2 1
+e 3 1
+q 4 1
+x 1 2
+y 2 2
+z 3 2
+##end
+t 5 0
+s-a
+a-p
+p-t
+s-c
+c-d
+d-e
+e-q
+q-t
+s-x
+x-y
+y-z
+z-p
+`
+
+func TestAntFarm_Analyze(t *testing.T) {
+	type impact struct {
+		Room     string
+		MaxPaths int
+		Turns    int
+	}
+	testCases := []struct {
+		name         string
+		input        string
+		wantMaxPaths int
+		wantCut      []string
+		wantTurns    int
+		wantCritical []impact
+	}{
+		{
+			name:         "cross",
+			input:        crossFarm,
+			wantMaxPaths: 2,
+			wantCut:      []string{"a", "c"},
+			wantTurns:    4,
+			wantCritical: []impact{{"a", 1, 5}, {"b", 1, 5}, {"c", 1, 5}, {"d", 1, 5}},
+		},
+		{
+			name:         "funnel",
+			input:        funnelFarm,
+			wantMaxPaths: 1,
+			wantCut:      []string{"a"},
+			wantTurns:    5,
+			wantCritical: []impact{{"a", 0, 0}, {"b", 1, 5}},
+		},
+		{
+			name:         "direct link",
+			input:        directFarm,
+			wantMaxPaths: 2,
+			wantCut:      nil,
+			wantTurns:    3,
+			wantCritical: []impact{{"a", 1, 4}},
+		},
+		{
+			name:         "rooms off the chosen paths",
+			input:        detourFarm,
+			wantMaxPaths: 2,
+			wantCut:      []string{"a", "b"},
+			wantTurns:    2,
+			wantCritical: []impact{{"a", 1, 4}, {"b", 1, 2}, {"c", 1, 2}, {"d", 1, 2}},
+		},
+		{
+			name:         "fewest paths first",
+			input:        bypassFarm,
+			wantMaxPaths: 2,
+			wantCut:      []string{"c", "p"},
+			wantTurns:    3,
+			wantCritical: []impact{{"p", 1, 5}, {"c", 1, 3}, {"d", 1, 3}, {"e", 1, 3}, {"q", 1, 3}, {"a", 2, 5}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, tc.input)
+			got, err := af.Analyze()
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+
+			var cut []string
+			if got.Cut != nil {
+				cut = make([]string, len(got.Cut))
+				for i, room := range got.Cut {
+					cut[i] = room.Name
+				}
+			}
+			critical := make([]impact, len(got.Critical))
+			for i, c := range got.Critical {
+				critical[i] = impact{c.Room.Name, c.MaxPaths, c.Turns}
+			}
+
+			if got.MaxPaths != tc.wantMaxPaths || got.Turns != tc.wantTurns {
+				t.Errorf("Analyze() = %d paths, %d turns, want %d, %d", got.MaxPaths, got.Turns, tc.wantMaxPaths, tc.wantTurns)
+			}
+			if !reflect.DeepEqual(cut, tc.wantCut) {
+				t.Errorf("Analyze() cut = %v, want %v", cut, tc.wantCut)
+			}
+			if !reflect.DeepEqual(critical, tc.wantCritical) {
+				t.Errorf("Analyze() critical = %v, want %v", critical, tc.wantCritical)
+			}
+		})
+	}
+}
+
+func TestAntFarm_without(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	clone := af.without(af.Rooms["b"])
+
+	if _, exists := clone.Rooms["b"]; exists {
+		t.Errorf("without() kept the removed room")
+	}
+	if got := len(af.Rooms["a"].Connected); got != 3 {
+		t.Errorf("without() changed the original farm: a has %d links, want 3", got)
+	}
+	for _, room := range clone.Rooms {
+		for _, next := range room.Connected {
+			if clone.Rooms[next.Name] != next {
+				t.Errorf("without() left %s linked to a room outside the copy", room.Name)
+			}
+		}
+	}
+}
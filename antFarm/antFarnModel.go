@@ -1,13 +1,42 @@
 package antfarm
 
 import (
+	"sync"
+
 	"test/models"
 )
 
+// AntFarm is the parsed colony graph. Simulations never modify it, so one
+// farm can be simulated many times and shared between goroutines; edits and
+// event scheduling take the write lock.
 type AntFarm struct {
 	NumAnts int
-	Ants    []*models.Ant
-	Rooms   map[string]*models.Room
-	Start   *models.Room
-	End     *models.Room
+	// MaxAnts caps the number of ants accepted from the input or WithAnts;
+	// zero means DefaultMaxAnts
+	MaxAnts int
+	// Workers caps the goroutines the exhaustive path search runs on; zero
+	// means one per CPU. The paths found do not depend on it.
+	Workers int
+	// Limits bounds the exhaustive path search
+	Limits SearchLimits
+	// Ants is an optional roster filled by initializeAnts. Parsing leaves it
+	// empty and simulations create their own ants only when they need them.
+	Ants   []*models.Ant
+	Rooms  map[string]*models.Room
+	Start  *models.Room
+	End    *models.Room
+	Events []models.Event
+
+	// source remembers the input lines rooms and tunnels were defined on
+	source sourceLines
+
+	// weights counts the extra rooms each room of a simplified farm stands
+	// for; it is nil for farms that were not simplified
+	weights map[*models.Room]int
+
+	// planner is created by the first edit through AddRoom, AddLink and
+	// friends; from then on it supplies the paths instead of a fresh search
+	planner *planner
+
+	mu sync.RWMutex
 }
@@ -0,0 +1,115 @@
+package antfarm
+
+import (
+	"errors"
+
+	"test/models"
+)
+
+// SolveAnytime solves in the background and sends ever better solutions on
+// the returned channel, each taking fewer turns than the last, or as many in
+// fewer moves. The first comes from the single shortest path. The disjoint
+// path sets of the k-shortest search follow, then the paths of the path
+// finder in opts, the default search without one, each spread over the ants
+// by every assigner. The channel is closed once the last stage is done or
+// the run's context ends, so give WithContext a deadline to bound the work.
+//
+// The farm stays read-locked until the channel is closed, so callers must
+// keep reading or end the context.
+func (af *AntFarm) SolveAnytime(opts ...Option) (<-chan models.Solution, error) {
+	cfg, err := af.newRunConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	af.mu.RLock()
+	if af.Start == nil || af.End == nil || af.routeAround(af.Start, newObstacles()) == nil {
+		af.mu.RUnlock()
+		return nil, errors.New("ERROR: no valid path found between start and end")
+	}
+	if cfg.numAnts == 0 {
+		af.mu.RUnlock()
+		return nil, errors.New("no ants available")
+	}
+
+	solutions := make(chan models.Solution)
+	go func() {
+		defer af.mu.RUnlock()
+		defer close(solutions)
+		s := &anytime{cfg: cfg, farm: af, out: solutions}
+		s.run()
+	}()
+	return solutions, nil
+}
+
+// anytime is the state of one SolveAnytime search
+type anytime struct {
+	cfg     runConfig
+	farm    *AntFarm
+	reduced *simplified // set when the search runs on a simplified copy
+	out     chan<- models.Solution
+	best    models.Solution
+	sent    bool
+}
+
+// run works through the stages, from the quickest to the most thorough
+func (s *anytime) run() {
+	af := s.farm
+	if s.cfg.simplify {
+		s.reduced = af.simplify()
+		af = s.reduced.farm
+	}
+	ctx := s.cfg.ctx
+
+	route := af.routeAround(af.Start, newObstacles())
+	if !s.offer([]models.Path{{Rooms: route, Length: af.pathLength(route)}}) {
+		return
+	}
+
+	g := newFlowGraph(af, s.cfg.routing)
+	for ctx.Err() == nil && g.augment() {
+		if !s.offer(g.paths()) {
+			return
+		}
+	}
+
+	if ctx.Err() != nil {
+		return
+	}
+	if s.cfg.finder != nil {
+		s.offer(s.cfg.finder.FindPaths(ctx, af, s.cfg.numAnts, s.cfg.routing))
+	} else {
+		s.offer(af.searchPaths(ctx, s.cfg.routing))
+	}
+}
+
+// offer assigns the ants to paths with each assigner and publishes the
+// result if it beats the best so far. It reports false once the context has
+// ended and nothing more should be sent.
+func (s *anytime) offer(paths []models.Path) bool {
+	if len(paths) == 0 {
+		return s.cfg.ctx.Err() == nil
+	}
+	if s.reduced != nil {
+		paths = s.reduced.expand(s.farm, paths)
+	}
+
+	assigners := append([]Assigner{s.cfg.assigner}, Assigners...)
+	improved := false
+	for _, assigner := range assigners {
+		sol := assigner.Assign(paths, s.cfg.numAnts)
+		if !s.sent || sol.Turns < s.best.Turns || sol.Turns == s.best.Turns && sol.Moves < s.best.Moves {
+			s.best, s.sent, improved = sol, true, true
+		}
+	}
+	if !improved {
+		return s.cfg.ctx.Err() == nil
+	}
+
+	select {
+	case s.out <- s.best:
+		return true
+	case <-s.cfg.ctx.Done():
+		return false
+	}
+}
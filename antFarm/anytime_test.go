@@ -0,0 +1,78 @@
+package antfarm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"test/generator"
+)
+
+func TestAntFarm_SolveAnytime(t *testing.T) {
+	af := parseFarm(t, generatedInput(t, generator.Config{Preset: generator.GreedyTrap, Seed: 1}))
+	want, err := af.Turns(WithPathFinder(KShortestDisjoint))
+	if err != nil {
+		t.Fatalf("Turns() error = %v", err)
+	}
+
+	for _, opts := range [][]Option{nil, {WithSimplify()}} {
+		solutions, err := af.SolveAnytime(opts...)
+		if err != nil {
+			t.Fatalf("SolveAnytime() error = %v", err)
+		}
+
+		var turns []int
+		for sol := range solutions {
+			if len(turns) == 0 && len(sol.Paths) != 1 {
+				t.Errorf("SolveAnytime() first solution has %d paths, want 1", len(sol.Paths))
+			}
+			if len(turns) > 0 && sol.Turns > turns[len(turns)-1] {
+				t.Errorf("SolveAnytime() went from %d to %d turns", turns[len(turns)-1], sol.Turns)
+			}
+			turns = append(turns, sol.Turns)
+
+			var moves strings.Builder
+			if err := af.WriteSolution(&moves, sol); err != nil {
+				t.Fatalf("WriteSolution() error = %v", err)
+			}
+			v, err := af.Verify(strings.NewReader(moves.String()))
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if v.Turns != sol.Turns {
+				t.Errorf("WriteSolution() took %d turns, solution says %d", v.Turns, sol.Turns)
+			}
+		}
+		if len(turns) < 2 || turns[len(turns)-1] != want {
+			t.Errorf("SolveAnytime() turns = %v, want improvements down to %d", turns, want)
+		}
+	}
+}
+
+func TestAntFarm_SolveAnytime_cancelled(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	solutions, err := af.SolveAnytime(WithContext(ctx))
+	if err != nil {
+		t.Fatalf("SolveAnytime() error = %v", err)
+	}
+	count := 0
+	for range solutions {
+		count++
+	}
+	if count > 1 {
+		t.Errorf("SolveAnytime() sent %d solutions after the context ended, want at most 1", count)
+	}
+	if err := af.AddRoom("z", 9, 9); err != nil {
+		t.Errorf("AddRoom() after SolveAnytime() error = %v", err)
+	}
+}
+
+func TestAntFarm_SolveAnytime_noPath(t *testing.T) {
+	af := parseFarm(t, "2\n##start\ns 0 0\na 1 0\n##end\nt 2 0\ns-a\n")
+	if _, err := af.SolveAnytime(); err == nil {
+		t.Error("SolveAnytime() error = nil, want no path")
+	}
+}
@@ -0,0 +1,160 @@
+package antfarm
+
+import (
+	"fmt"
+	"sort"
+
+	"test/models"
+)
+
+// Assigner decides how many ants take each of a set of paths that share
+// nothing the run's Routing keeps apart
+type Assigner interface {
+	// Name identifies the assigner, as accepted by LookupAssigner
+	Name() string
+	// Assign spreads numAnts ants over paths, which need not be sorted
+	Assign(paths []models.Path, numAnts int) models.Solution
+}
+
+var (
+	// Greedy sends each ant in turn down the path where it arrives first,
+	// ties going to the lower index
+	Greedy Assigner = greedy{}
+	// Balanced fills the shortest paths worth using up to a common arrival
+	// turn worked out in closed form, then takes the ants that do not fit
+	// back from the longest paths one at a time
+	Balanced Assigner = balanced{}
+	// Exact searches for the fewest turns directly and, of the assignments
+	// that reach it, picks the one where the ants cross the fewest tunnels
+	Exact Assigner = exact{}
+)
+
+// Assigners lists every assigner, the default first
+var Assigners = []Assigner{Greedy, Balanced, Exact}
+
+// LookupAssigner returns the assigner with the given name
+func LookupAssigner(name string) (Assigner, error) {
+	for _, assigner := range Assigners {
+		if assigner.Name() == name {
+			return assigner, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown assigner %q", name)
+}
+
+type greedy struct{}
+
+func (greedy) Name() string { return "greedy" }
+
+func (greedy) Assign(paths []models.Path, numAnts int) models.Solution {
+	return newSolution(paths, antCounts(pathLengths(paths), numAnts))
+}
+
+type balanced struct{}
+
+func (balanced) Name() string { return "balanced" }
+
+func (balanced) Assign(paths []models.Path, numAnts int) models.Solution {
+	lengths := pathLengths(paths)
+	counts := make([]int, len(paths))
+	if len(paths) == 0 || numAnts <= 0 {
+		return newSolution(paths, counts)
+	}
+
+	order := byLength(lengths)
+	sorted := make([]int, len(order))
+	for i, j := range order {
+		sorted[i] = lengths[j]
+	}
+	k, turns := bestPrefix(sorted, numAnts)
+
+	surplus := -numAnts
+	for _, j := range order[:k] {
+		counts[j] = turns - lengths[j] + 1
+		surplus += counts[j]
+	}
+	for i := k - 1; surplus > 0; i-- {
+		if i < 0 {
+			i = k - 1
+		}
+		if j := order[i]; counts[j] > 0 {
+			counts[j]--
+			surplus--
+		}
+	}
+	return newSolution(paths, counts)
+}
+
+type exact struct{}
+
+func (exact) Name() string { return "exact" }
+
+// Assign binary searches the last arrival turn: by turn t a path of length l
+// can deliver t-l+1 ants. Once the fewest turns are known, ants are placed on
+// the shortest paths first, which leaves the fewest tunnels crossed.
+func (exact) Assign(paths []models.Path, numAnts int) models.Solution {
+	lengths := pathLengths(paths)
+	counts := make([]int, len(paths))
+	if len(paths) == 0 || numAnts <= 0 {
+		return newSolution(paths, counts)
+	}
+
+	order := byLength(lengths)
+	shortest := lengths[order[0]]
+	turns := shortest + numAnts - 1
+	low := shortest
+	for low < turns {
+		mid := low + (turns-low)/2
+		capacity := 0
+		for _, length := range lengths {
+			capacity += max(mid-length+1, 0)
+		}
+		if capacity >= numAnts {
+			turns = mid
+		} else {
+			low = mid + 1
+		}
+	}
+
+	left := numAnts
+	for _, j := range order {
+		counts[j] = min(max(turns-lengths[j]+1, 0), left)
+		left -= counts[j]
+	}
+	return newSolution(paths, counts)
+}
+
+// newSolution works out the turns and moves of sending counts[j] ants down
+// paths[j]. The k-th ant on a path arrives length+k-1 turns in.
+func newSolution(paths []models.Path, counts []int) models.Solution {
+	sol := models.Solution{Paths: paths, Counts: counts}
+	for j, count := range counts {
+		if count > 0 {
+			sol.Turns = max(sol.Turns, paths[j].Length+count-1)
+			sol.Moves += paths[j].Length * count
+		}
+	}
+	return sol
+}
+
+// pathLengths returns the length of every path
+func pathLengths(paths []models.Path) []int {
+	lengths := make([]int, len(paths))
+	for j, path := range paths {
+		lengths[j] = path.Length
+	}
+	return lengths
+}
+
+// byLength returns the path indices ordered by length, keeping the given
+// order between equal lengths
+func byLength(lengths []int) []int {
+	order := make([]int, len(lengths))
+	for j := range order {
+		order[j] = j
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return lengths[order[a]] < lengths[order[b]]
+	})
+	return order
+}
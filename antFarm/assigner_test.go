@@ -0,0 +1,130 @@
+package antfarm
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"test/models"
+)
+
+// lengthPaths builds bare paths of the given lengths
+func lengthPaths(lengths ...int) []models.Path {
+	paths := make([]models.Path, len(lengths))
+	for j, length := range lengths {
+		paths[j] = models.Path{Length: length}
+	}
+	return paths
+}
+
+func TestAssigners(t *testing.T) {
+	testCases := []struct {
+		name      string
+		assigner  Assigner
+		lengths   []int
+		numAnts   int
+		wantCount []int
+		wantTurns int
+		wantMoves int
+	}{
+		{"greedy", Greedy, []int{2, 3, 5}, 6, []int{4, 2, 0}, 5, 14},
+		{"balanced", Balanced, []int{2, 3, 5}, 6, []int{4, 2, 0}, 5, 14},
+		{"exact", Exact, []int{2, 3, 5}, 6, []int{4, 2, 0}, 5, 14},
+		{"greedy skips long path", Greedy, []int{2, 10}, 5, []int{5, 0}, 6, 10},
+		{"balanced skips long path", Balanced, []int{2, 10}, 5, []int{5, 0}, 6, 10},
+		{"exact skips long path", Exact, []int{2, 10}, 5, []int{5, 0}, 6, 10},
+		{"greedy equal arrivals", Greedy, []int{3, 3, 4}, 4, []int{2, 2, 0}, 4, 12},
+		{"balanced takes surplus off longest", Balanced, []int{3, 3, 4}, 5, []int{2, 2, 1}, 4, 16},
+		{"exact unsorted paths", Exact, []int{5, 2}, 3, []int{0, 3}, 4, 6},
+		{"exact one ant", Exact, []int{4, 1}, 1, []int{0, 1}, 1, 1},
+		{"no paths", Greedy, nil, 3, []int{}, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths := lengthPaths(tc.lengths...)
+			got := tc.assigner.Assign(paths, tc.numAnts)
+			if !reflect.DeepEqual(got.Counts, tc.wantCount) {
+				t.Errorf("%s.Assign() counts = %v, want %v", tc.assigner.Name(), got.Counts, tc.wantCount)
+			}
+			if got.Turns != tc.wantTurns || got.Moves != tc.wantMoves {
+				t.Errorf("%s.Assign() = %d turns, %d moves, want %d, %d",
+					tc.assigner.Name(), got.Turns, got.Moves, tc.wantTurns, tc.wantMoves)
+			}
+		})
+	}
+}
+
+func TestAssigners_sameTurns(t *testing.T) {
+	lengths := []int{3, 4, 4, 7, 9, 12}
+	for numAnts := 1; numAnts <= 60; numAnts++ {
+		want := Greedy.Assign(lengthPaths(lengths...), numAnts)
+		for _, assigner := range Assigners {
+			got := assigner.Assign(lengthPaths(lengths...), numAnts)
+			sum := 0
+			for _, count := range got.Counts {
+				sum += count
+			}
+			if sum != numAnts || got.Turns != want.Turns {
+				t.Errorf("%d ants: %s placed %d ants in %d turns, want %d turns",
+					numAnts, assigner.Name(), sum, got.Turns, want.Turns)
+			}
+		}
+	}
+}
+
+func TestAntFarm_Solve(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	for _, assigner := range Assigners {
+		sol, err := af.Solve(WithAssigner(assigner), WithAnts(7))
+		if err != nil {
+			t.Fatalf("Solve(%s) error = %v", assigner.Name(), err)
+		}
+
+		var moves strings.Builder
+		if err := af.WriteMovement(&moves, WithAssigner(assigner), WithAnts(7)); err != nil {
+			t.Fatalf("WriteMovement(%s) error = %v", assigner.Name(), err)
+		}
+		got, err := af.Verify(strings.NewReader(moves.String()), WithAnts(7))
+		if err != nil {
+			t.Fatalf("Verify(%s) error = %v", assigner.Name(), err)
+		}
+		if got.Turns != sol.Turns {
+			t.Errorf("%s: predicted %d turns, simulation took %d", assigner.Name(), sol.Turns, got.Turns)
+		}
+	}
+}
+
+func TestLookupAssigner(t *testing.T) {
+	for _, assigner := range Assigners {
+		if got, err := LookupAssigner(assigner.Name()); err != nil || got != assigner {
+			t.Errorf("LookupAssigner(%q) = %v, %v", assigner.Name(), got, err)
+		}
+	}
+	if _, err := LookupAssigner("random"); err == nil {
+		t.Errorf("LookupAssigner() accepted an unknown name")
+	}
+}
+
+func TestSolution_Dispatch(t *testing.T) {
+	sol := newSolution(lengthPaths(2, 3, 5), []int{3, 2, 1})
+	want := []int{0, 0, 1, 0, 1, 2}
+	if got := sol.Dispatch().PathOf; !reflect.DeepEqual(got, want) {
+		t.Errorf("Dispatch() = %v, want %v", got, want)
+	}
+}
+
+func BenchmarkAssigner_Assign(b *testing.B) {
+	for _, cfg := range benchFarms {
+		af := parseFarm(b, generatedInput(b, cfg))
+		paths := af.findAllPaths()
+		for _, assigner := range Assigners {
+			b.Run(string(cfg.Preset)+"/"+assigner.Name(), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					assigner.Assign(paths, af.NumAnts)
+				}
+			})
+		}
+	}
+}
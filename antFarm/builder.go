@@ -0,0 +1,95 @@
+package antfarm
+
+import "fmt"
+
+// Builder assembles an ant farm in code, running the same checks as the input
+// parser. The first failing step is remembered and returned by Build; later
+// steps are ignored.
+//
+//	farm, err := NewBuilder().Ants(10).Start("s", 0, 0).Room("a", 1, 2).
+//		End("e", 5, 5).Link("s", "a").Link("a", "e").Build()
+type Builder struct {
+	farm *AntFarm
+	err  error
+}
+
+// NewBuilder creates a builder for an empty farm
+func NewBuilder() *Builder {
+	return &Builder{farm: NewAntFarm()}
+}
+
+// MaxAnts raises or lowers the limit checked by Ants, like AntFarm.MaxAnts
+func (b *Builder) MaxAnts(maxAnts int) *Builder {
+	if b.err == nil {
+		b.farm.MaxAnts = maxAnts
+	}
+	return b
+}
+
+// Ants sets the number of ants in the start room
+func (b *Builder) Ants(numAnts int) *Builder {
+	if b.err == nil {
+		if err := checkNumAnts(numAnts, b.farm.maxAnts()); err != nil {
+			b.err = fmt.Errorf("number of ants: %w", err)
+			return b
+		}
+		b.farm.NumAnts = numAnts
+	}
+	return b
+}
+
+// Room adds an ordinary room
+func (b *Builder) Room(name string, x, y int) *Builder {
+	return b.room(name, x, y, false, false)
+}
+
+// Start adds the start room
+func (b *Builder) Start(name string, x, y int) *Builder {
+	return b.room(name, x, y, true, false)
+}
+
+// End adds the end room
+func (b *Builder) End(name string, x, y int) *Builder {
+	return b.room(name, x, y, false, true)
+}
+
+// Link connects two rooms that have already been added
+func (b *Builder) Link(name1, name2 string) *Builder {
+	if b.err == nil {
+		if err := b.farm.addLink(name1, name2); err != nil {
+			b.err = fmt.Errorf("link %s-%s: %w", name1, name2, err)
+		}
+	}
+	return b
+}
+
+// Build validates the farm and returns it ready to simulate. The builder must
+// not be used afterwards.
+func (b *Builder) Build() (*AntFarm, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	if err := checkNumAnts(b.farm.NumAnts, b.farm.maxAnts()); err != nil {
+		return nil, fmt.Errorf("number of ants: %w", err)
+	}
+	if err := b.farm.validate(); err != nil {
+		return nil, err
+	}
+	return b.farm, nil
+}
+
+// room adds a room with the given role
+func (b *Builder) room(name string, x, y int, isStart, isEnd bool) *Builder {
+	if b.err != nil {
+		return b
+	}
+
+	room, err := b.farm.newRoom(name, x, y, isStart, isEnd)
+	if err == nil {
+		err = b.farm.addRoom(room)
+	}
+	if err != nil {
+		b.err = fmt.Errorf("room %s: %w", name, err)
+	}
+	return b
+}
@@ -0,0 +1,97 @@
+package antfarm
+
+import (
+	"errors"
+	"testing"
+
+	"test/models"
+)
+
+func TestBuilder_Build(t *testing.T) {
+	testCases := []struct {
+		name     string
+		build    func() *Builder
+		wantAnts int
+		wantErr  error
+	}{
+		{"valid farm", func() *Builder {
+			return NewBuilder().Ants(3).Start("s", 0, 0).Room("a", 1, 2).End("e", 5, 5).Link("s", "a").Link("a", "e")
+		}, 3, nil},
+		{"missing ants", func() *Builder {
+			return NewBuilder().Start("s", 0, 0).End("e", 5, 5)
+		}, 0, models.ErrNonPositiveAnts},
+		{"too many ants", func() *Builder {
+			return NewBuilder().Ants(10001).Start("s", 0, 0).End("e", 5, 5)
+		}, 0, models.ErrTooManyAnts},
+		{"duplicate room", func() *Builder {
+			return NewBuilder().Ants(1).Start("s", 0, 0).Room("s", 1, 1)
+		}, 0, models.ErrDuplicateRoom},
+		{"invalid room name", func() *Builder {
+			return NewBuilder().Ants(1).Room("a-b", 0, 0)
+		}, 0, models.ErrInvalidRoomName},
+		{"multiple start rooms", func() *Builder {
+			return NewBuilder().Ants(1).Start("s", 0, 0).Start("t", 1, 1)
+		}, 0, models.ErrMultipleStart},
+		{"multiple end rooms", func() *Builder {
+			return NewBuilder().Ants(1).End("e", 0, 0).End("f", 1, 1)
+		}, 0, models.ErrMultipleEnd},
+		{"link to unknown room", func() *Builder {
+			return NewBuilder().Ants(1).Start("s", 0, 0).End("e", 1, 1).Link("s", "x")
+		}, 0, models.ErrUnknownRoom},
+		{"duplicate link", func() *Builder {
+			return NewBuilder().Ants(1).Start("s", 0, 0).End("e", 1, 1).Link("s", "e").Link("e", "s")
+		}, 0, models.ErrDuplicateLink},
+		{"self link", func() *Builder {
+			return NewBuilder().Ants(1).Start("s", 0, 0).End("e", 1, 1).Link("s", "s")
+		}, 0, models.ErrInvalidLink},
+		{"no start room", func() *Builder {
+			return NewBuilder().Ants(1).End("e", 1, 1)
+		}, 0, models.ErrNoStart},
+		{"no end room", func() *Builder {
+			return NewBuilder().Ants(1).Start("s", 1, 1)
+		}, 0, models.ErrNoEnd},
+		{"raised ant limit", func() *Builder {
+			return NewBuilder().MaxAnts(20000).Ants(3).Ants(15000).Start("s", 0, 0).End("e", 5, 5)
+		}, 15000, nil},
+		{"first error wins", func() *Builder {
+			return NewBuilder().Ants(0).Room("a-b", 0, 0).Start("s", 0, 0)
+		}, 0, models.ErrNonPositiveAnts},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af, err := tc.build().Build()
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Build() error = %v, want %v", err, tc.wantErr)
+			}
+			if err != nil {
+				if af != nil {
+					t.Errorf("Build() returned a farm along with error %v", err)
+				}
+				return
+			}
+			if af.NumAnts != tc.wantAnts {
+				t.Errorf("Build() set %d ants, want %d", af.NumAnts, tc.wantAnts)
+			}
+		})
+	}
+}
+
+func TestBuilder_simulate(t *testing.T) {
+	af, err := NewBuilder().Ants(3).
+		Start("s", 0, 0).Room("a", 1, 0).Room("b", 1, 1).End("e", 2, 0).
+		Link("s", "a").Link("a", "e").Link("s", "b").Link("b", "e").
+		Build()
+	if err != nil {
+		t.Fatalf("Build() error = %v", err)
+	}
+
+	got, err := af.SimulateMovement()
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+	want := "L1-a L2-b\nL1-e L2-e L3-a\nL3-e\n"
+	if got != want {
+		t.Errorf("SimulateMovement() got =\n%v\nwant =\n%v", got, want)
+	}
+}
@@ -1,29 +1,342 @@
 package antfarm
 
-import "test/models"
+import (
+	"context"
+	"runtime"
+	"sync"
+	"sync/atomic"
 
-// dfs performs depth-first search to find all possible paths
-func (af *AntFarm) dfs(current, end *models.Room, visited map[*models.Room]bool, path []*models.Room, paths *[]models.Path) {
-	visited[current] = true
+	"test/models"
+)
+
+// SearchLimits bounds the exhaustive path search behind DFSGreedy and the
+// default planning. The zero value searches every simple path.
+type SearchLimits struct {
+	// MaxStretch skips paths longer than this multiple of the shortest
+	// path's length, e.g. 1.5; zero means no limit. The shortest paths are
+	// always kept.
+	MaxStretch float64
+	// MaxPaths stops the search after this many paths, the first ones in
+	// depth-first order; zero means no limit
+	MaxPaths int
+}
+
+// roomGraph is the farm with rooms numbered and tunnels as lists of room
+// numbers, so the search runs on slices instead of maps
+type roomGraph struct {
+	rooms  []*models.Room
+	number map[*models.Room]int32
+	links  [][]int32 // in the order of each room's Connected
+	weight []int
+}
+
+// newRoomGraph numbers the farm's rooms along with any others given that it
+// does not list
+func (af *AntFarm) newRoomGraph(extra ...*models.Room) *roomGraph {
+	g := &roomGraph{number: make(map[*models.Room]int32, len(af.Rooms))}
+	add := func(room *models.Room) {
+		if _, seen := g.number[room]; room != nil && !seen {
+			g.number[room] = int32(len(g.rooms))
+			g.rooms = append(g.rooms, room)
+		}
+	}
+	for _, room := range af.Rooms {
+		add(room)
+	}
+	for _, room := range extra {
+		add(room)
+	}
+	for i := 0; i < len(g.rooms); i++ {
+		for _, next := range g.rooms[i].Connected {
+			add(next)
+		}
+	}
+
+	g.links = make([][]int32, len(g.rooms))
+	g.weight = make([]int, len(g.rooms))
+	for i, room := range g.rooms {
+		g.links[i] = make([]int32, len(room.Connected))
+		for j, next := range room.Connected {
+			g.links[i][j] = g.number[next]
+		}
+		g.weight[i] = af.weights[room]
+	}
+	return g
+}
+
+// pathSearch is what a depth-first search needs besides where it is
+type pathSearch struct {
+	ctx       context.Context
+	graph     *roomGraph
+	end       int32
+	toEnd     []int // moves left from each room to the end, -1 if it cannot; nil prunes nothing
+	maxLength int   // longest path kept, zero for any
+	maxPaths  int   // most paths kept, zero for any
+}
+
+// newPathSearch prepares a search from start to end within the farm's
+// limits. It returns nil when the end cannot be reached.
+func (af *AntFarm) newPathSearch(ctx context.Context) *pathSearch {
+	distances := af.distancesToEnd()
+	shortest, reachable := distances[af.Start]
+	if !reachable {
+		return nil
+	}
+
+	g := af.newRoomGraph()
+	s := &pathSearch{ctx: ctx, graph: g, end: g.number[af.End], maxPaths: max(af.Limits.MaxPaths, 0)}
+	s.toEnd = make([]int, len(g.rooms))
+	for i, room := range g.rooms {
+		if dist, reachable := distances[room]; reachable {
+			s.toEnd[i] = dist
+		} else {
+			s.toEnd[i] = -1
+		}
+	}
+	if af.Limits.MaxStretch > 0 {
+		s.maxLength = max(int(af.Limits.MaxStretch*float64(shortest)), shortest)
+	}
+	return s
+}
+
+// searchFrame is a room on the search's stack and the next of its tunnels
+// to try
+type searchFrame struct {
+	room   int32
+	next   int32
+	length int
+}
+
+// dfsBuffers is the scratch space of one search, kept in searchBuffers
+// between searches so a busy farm does not allocate it again and again
+type dfsBuffers struct {
+	visited []bool
+	stack   []searchFrame
+	path    []int32
+}
+
+var searchBuffers = sync.Pool{New: func() any { return new(dfsBuffers) }}
+
+// buffers takes scratch space big enough for the graph from the pool. Every
+// search leaves visited all false again, so it needs no clearing.
+func (s *pathSearch) buffers() *dfsBuffers {
+	buf := searchBuffers.Get().(*dfsBuffers)
+	if len(buf.visited) < len(s.graph.rooms) {
+		buf.visited = make([]bool, len(s.graph.rooms))
+	}
+	return buf
+}
+
+// dfs adds the paths that continue path through current to paths. length is
+// the length of path once current is added. Rooms that cannot reach the end,
+// or only by a path that is too long, are not entered. The search keeps its
+// own stack rather than recursing, so long corridors cannot exhaust the
+// goroutine's stack, and rooms in path must already be marked visited.
+func (s *pathSearch) dfs(current int32, length int, buf *dfsBuffers, path []int32, paths *[]models.Path) {
+	const checkEvery = 1 << 10 // steps between looks at the context
+	g, visited := s.graph, buf.visited
+	stack := buf.stack[:0]
+	defer func() { buf.stack, buf.path = stack[:0], path[:0] }()
+
+	full := func() bool {
+		return s.maxPaths > 0 && len(*paths) >= s.maxPaths
+	}
+	record := func(path []int32, length int) {
+		rooms := make([]*models.Room, len(path))
+		for i, n := range path {
+			rooms[i] = g.rooms[n]
+		}
+		*paths = append(*paths, models.Path{Rooms: rooms, Length: length})
+	}
+
+	if s.ctx.Err() != nil || full() {
+		return
+	}
 	path = append(path, current)
+	if current == s.end {
+		record(path, length)
+		return
+	}
+	visited[current] = true
+	stack = append(stack, searchFrame{room: current, length: length})
 
-	if current == end {
-		// Create a new path
-		newPath := models.Path{
-			Rooms:  make([]*models.Room, len(path)),
-			Length: len(path) - 1,
-			InUse:  false,
+	for steps := 1; len(stack) > 0; steps++ {
+		if steps%checkEvery == 0 && s.ctx.Err() != nil || full() {
+			break
 		}
-		copy(newPath.Rooms, path)
-		*paths = append(*paths, newPath)
-	} else {
-		for _, next := range current.Connected {
-			if !visited[next] {
-				af.dfs(next, end, visited, path, paths)
+		top := &stack[len(stack)-1]
+		links := g.links[top.room]
+		if int(top.next) == len(links) {
+			// Backtrack
+			visited[top.room] = false
+			stack = stack[:len(stack)-1]
+			path = path[:len(path)-1]
+			continue
+		}
+
+		next := links[top.next]
+		top.next++
+		nextLength := top.length + 1 + g.weight[next]
+		if visited[next] || !s.within(next, nextLength) {
+			continue
+		}
+		if next == s.end {
+			record(append(path, next), nextLength)
+			continue
+		}
+		visited[next] = true
+		path = append(path, next)
+		stack = append(stack, searchFrame{room: next, length: nextLength})
+	}
+
+	// A search cut short still leaves visited as it found it
+	for _, frame := range stack {
+		visited[frame.room] = false
+	}
+}
+
+// within reports whether a path reaching room with the given length can
+// still end in time
+func (s *pathSearch) within(room int32, length int) bool {
+	if s.toEnd == nil {
+		return true
+	}
+	left := s.toEnd[room]
+	return left >= 0 && (s.maxLength == 0 || length+left <= s.maxLength)
+}
+
+// distancesToEnd runs a breadth-first search back from the end and returns
+// the fewest moves from each room that can reach it, counting the rooms a
+// simplified room stands for
+func (af *AntFarm) distancesToEnd() map[*models.Room]int {
+	toEnd := make(map[*models.Room]int, len(af.Rooms))
+	if af.End == nil {
+		return toEnd
+	}
+
+	type visit struct {
+		room       *models.Room
+		wait, dist int
+	}
+	toEnd[af.End] = 0
+	queue := []visit{{af.End, af.weights[af.End], 0}}
+	for len(queue) > 0 {
+		v := queue[0]
+		queue = queue[1:]
+		if v.wait > 0 {
+			queue = append(queue, visit{v.room, v.wait - 1, v.dist + 1})
+			continue
+		}
+		for _, prev := range v.room.Connected {
+			if _, seen := toEnd[prev]; !seen {
+				toEnd[prev] = v.dist + 1
+				queue = append(queue, visit{prev, af.weights[prev], v.dist + 1})
 			}
 		}
 	}
+	return toEnd
+}
 
-	// Backtrack
-	visited[current] = false
+// workers returns how many goroutines the path search may use
+func (af *AntFarm) workers() int {
+	if af.Workers > 0 {
+		return af.Workers
+	}
+	return runtime.GOMAXPROCS(0)
+}
+
+// dfsParallel finds the same paths as a dfs from start to end, in the same
+// order, on up to workers goroutines. The search is split into the routes
+// leading out of the start a few rooms deep; each worker takes the next
+// route not yet searched and writes its paths to that route's own slot, and
+// the slots are joined in order at the end, so no locking is needed.
+func (af *AntFarm) dfsParallel(ctx context.Context, workers int) []models.Path {
+	if af.Start == nil || af.End == nil {
+		return make([]models.Path, 0)
+	}
+	s := af.newPathSearch(ctx)
+	if s == nil {
+		return make([]models.Path, 0)
+	}
+
+	prefixes := af.splitSearch(workers)
+	found := make([][]models.Path, len(prefixes))
+	search := func(i int) {
+		prefix := prefixes[i]
+		last, length := prefix[len(prefix)-1], af.pathLength(prefix)
+		if !s.within(s.graph.number[last], length) {
+			return
+		}
+		buf := s.buffers()
+		defer searchBuffers.Put(buf)
+		path := buf.path[:0]
+		for _, room := range prefix[:len(prefix)-1] {
+			path = append(path, s.graph.number[room])
+			buf.visited[s.graph.number[room]] = true
+		}
+		s.dfs(s.graph.number[last], length, buf, path, &found[i])
+		for _, n := range path {
+			buf.visited[n] = false
+		}
+	}
+
+	if workers <= 1 || len(prefixes) == 1 {
+		for i := range prefixes {
+			search(i)
+		}
+	} else {
+		var next atomic.Int64
+		var wg sync.WaitGroup
+		for w := 0; w < min(workers, len(prefixes)); w++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for i := int(next.Add(1) - 1); i < len(prefixes); i = int(next.Add(1) - 1) {
+					search(i)
+				}
+			}()
+		}
+		wg.Wait()
+	}
+
+	// Each slot holds at most maxPaths paths, so the first maxPaths joined
+	// are the ones a single search would have kept
+	paths := make([]models.Path, 0)
+	for _, shard := range found {
+		paths = append(paths, shard...)
+	}
+	if s.maxPaths > 0 && len(paths) > s.maxPaths {
+		paths = paths[:s.maxPaths]
+	}
+	return paths
+}
+
+// splitSearch returns the simple routes from the start that the parallel
+// search hands out, in the order dfs would follow them. Routes are extended a
+// room at a time until there are a few for every worker, so one busy branch
+// does not leave the others idle. A route that reaches the end is kept whole.
+func (af *AntFarm) splitSearch(workers int) [][]*models.Room {
+	const routesPerWorker, maxDepth = 4, 3
+	prefixes := [][]*models.Room{{af.Start}}
+	if workers <= 1 {
+		return prefixes
+	}
+
+	for depth := 0; depth < maxDepth && len(prefixes) < routesPerWorker*workers; depth++ {
+		longer := make([][]*models.Room, 0, len(prefixes))
+		for _, prefix := range prefixes {
+			last := prefix[len(prefix)-1]
+			if last == af.End {
+				longer = append(longer, prefix)
+				continue
+			}
+			for _, next := range last.Connected {
+				if !hasRoom(prefix, next) {
+					longer = append(longer, append(prefix[:len(prefix):len(prefix)], next))
+				}
+			}
+		}
+		prefixes = longer
+	}
+	return prefixes
 }
@@ -1,11 +1,28 @@
 package antfarm
 
 import (
+	"context"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
 	"testing"
 
 	"test/models"
 )
 
+// searchAll runs the iterative search over the whole farm on one goroutine,
+// pruning nothing
+func searchAll(af *AntFarm) []models.Path {
+	g := af.newRoomGraph()
+	s := &pathSearch{ctx: context.Background(), graph: g, end: g.number[af.End]}
+	buf := s.buffers()
+	defer searchBuffers.Put(buf)
+	paths := make([]models.Path, 0)
+	s.dfs(g.number[af.Start], af.weights[af.Start], buf, buf.path[:0], &paths)
+	return paths
+}
+
 func TestAntFarm_dfs(t *testing.T) {
 	type fields struct {
 		NumAnts int
@@ -14,13 +31,6 @@ func TestAntFarm_dfs(t *testing.T) {
 		Start   *models.Room
 		End     *models.Room
 	}
-	type args struct {
-		current *models.Room
-		end     *models.Room
-		visited map[*models.Room]bool
-		path    []*models.Room
-		paths   *[]models.Path
-	}
 
 	// Helper function to create rooms and connections
 	createRooms := func(roomNames []string, connections map[string][]string) map[string]*models.Room {
@@ -47,7 +57,6 @@ func TestAntFarm_dfs(t *testing.T) {
 	tests := []struct {
 		name     string
 		fields   fields
-		args     args
 		expected int // Expected number of paths
 	}{
 		{
@@ -62,11 +71,6 @@ func TestAntFarm_dfs(t *testing.T) {
 					},
 				),
 			},
-			args: args{
-				visited: make(map[*models.Room]bool),
-				path:    make([]*models.Room, 0),
-				paths:   &[]models.Path{},
-			},
 			expected: 1,
 		},
 		{
@@ -82,11 +86,6 @@ func TestAntFarm_dfs(t *testing.T) {
 					},
 				),
 			},
-			args: args{
-				visited: make(map[*models.Room]bool),
-				path:    make([]*models.Room, 0),
-				paths:   &[]models.Path{},
-			},
 			expected: 2,
 		},
 		{
@@ -102,11 +101,6 @@ func TestAntFarm_dfs(t *testing.T) {
 					},
 				),
 			},
-			args: args{
-				visited: make(map[*models.Room]bool),
-				path:    make([]*models.Room, 0),
-				paths:   &[]models.Path{},
-			},
 			expected: 2,
 		},
 		{
@@ -121,11 +115,6 @@ func TestAntFarm_dfs(t *testing.T) {
 					},
 				),
 			},
-			args: args{
-				visited: make(map[*models.Room]bool),
-				path:    make([]*models.Room, 0),
-				paths:   &[]models.Path{},
-			},
 			expected: 0,
 		},
 		{
@@ -143,11 +132,6 @@ func TestAntFarm_dfs(t *testing.T) {
 					},
 				),
 			},
-			args: args{
-				visited: make(map[*models.Room]bool),
-				path:    make([]*models.Room, 0),
-				paths:   &[]models.Path{},
-			},
 			expected: 4,
 		},
 	}
@@ -162,20 +146,16 @@ func TestAntFarm_dfs(t *testing.T) {
 				End:     tt.fields.Rooms["end"],
 			}
 
-			// Initialize args with proper start and end rooms
-			tt.args.current = af.Start
-			tt.args.end = af.End
-
 			// Run DFS
-			af.dfs(tt.args.current, tt.args.end, tt.args.visited, tt.args.path, tt.args.paths)
+			paths := searchAll(af)
 
 			// Check number of paths found
-			if len(*tt.args.paths) != tt.expected {
-				t.Errorf("dfs() found %v paths, expected %v paths", len(*tt.args.paths), tt.expected)
+			if len(paths) != tt.expected {
+				t.Errorf("dfs() found %v paths, expected %v paths", len(paths), tt.expected)
 			}
 
 			// Verify that each path is valid
-			for i, path := range *tt.args.paths {
+			for i, path := range paths {
 				// Check if path starts at start room and ends at end room
 				if path.Rooms[0] != af.Start {
 					t.Errorf("Path %d does not start at start room", i)
@@ -208,3 +188,102 @@ func TestAntFarm_dfs(t *testing.T) {
 		})
 	}
 }
+
+func TestAntFarm_dfsParallel(t *testing.T) {
+	for _, cfg := range benchFarms {
+		af := parseFarm(t, generatedInput(t, cfg))
+		want := searchAll(af)
+
+		for _, workers := range []int{1, 2, 3, 8} {
+			t.Run(fmt.Sprintf("%s/%d workers", cfg.Preset, workers), func(t *testing.T) {
+				got := af.dfsParallel(context.Background(), workers)
+				if !reflect.DeepEqual(pathNames(got), pathNames(want)) {
+					t.Errorf("dfsParallel() found %d paths, want the %d dfs() finds in the same order", len(got), len(want))
+				}
+			})
+		}
+	}
+}
+
+func TestAntFarm_dfsParallel_limits(t *testing.T) {
+	af := parseFarm(t, generatedInput(t, benchFarms[1]))
+	all := af.dfsParallel(context.Background(), 1)
+	shortest := all[0].Length
+	for _, path := range all {
+		shortest = min(shortest, path.Length)
+	}
+
+	testCases := []struct {
+		name   string
+		limits SearchLimits
+		keep   func(i int, path models.Path) bool
+	}{
+		{"shortest only", SearchLimits{MaxStretch: 1}, func(i int, path models.Path) bool {
+			return path.Length == shortest
+		}},
+		{"half as long again", SearchLimits{MaxStretch: 1.5}, func(i int, path models.Path) bool {
+			return path.Length <= shortest*3/2
+		}},
+		{"first ten", SearchLimits{MaxPaths: 10}, func(i int, path models.Path) bool {
+			return i < 10
+		}},
+	}
+
+	for _, tc := range testCases {
+		var want []models.Path
+		for i, path := range all {
+			if tc.keep(i, path) {
+				want = append(want, path)
+			}
+		}
+		for _, workers := range []int{1, 3} {
+			t.Run(fmt.Sprintf("%s/%d workers", tc.name, workers), func(t *testing.T) {
+				af.Limits = tc.limits
+				defer func() { af.Limits = SearchLimits{} }()
+				got := af.dfsParallel(context.Background(), workers)
+				if !reflect.DeepEqual(pathNames(got), pathNames(want)) {
+					t.Errorf("dfsParallel() = %d paths, want %d", len(got), len(want))
+				}
+			})
+		}
+	}
+}
+
+func TestAntFarm_distancesToEnd(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	if err := af.AddRoom("z", 9, 9); err != nil {
+		t.Fatalf("AddRoom() error = %v", err)
+	}
+
+	got := make(map[string]int)
+	for room, dist := range af.distancesToEnd() {
+		got[room.Name] = dist
+	}
+	want := map[string]int{"t": 0, "b": 1, "d": 1, "a": 2, "c": 2, "s": 3}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("distancesToEnd() = %v, want %v", got, want)
+	}
+}
+
+func TestAntFarm_findAllPaths_longCorridor(t *testing.T) {
+	// Two corridors of 100k rooms each, searched on a stack far too small
+	// for one call per room
+	const rooms = 100000
+	var input strings.Builder
+	input.WriteString("5\n##start\ns 0 0\n##end\nt 1 0\n")
+	for i := 0; i < rooms; i++ {
+		fmt.Fprintf(&input, "a%d %d 1\nb%d %d 2\n", i, i, i, i)
+	}
+	input.WriteString("s-a0\ns-b0\n")
+	for i := 1; i < rooms; i++ {
+		fmt.Fprintf(&input, "a%d-a%d\nb%d-b%d\n", i-1, i, i-1, i)
+	}
+	fmt.Fprintf(&input, "a%d-t\nb%d-t\n", rooms-1, rooms-1)
+	af := parseFarm(t, input.String())
+
+	defer debug.SetMaxStack(debug.SetMaxStack(1 << 20))
+	paths := af.findAllPaths()
+	if len(paths) != 2 || paths[0].Length != rooms+1 || paths[1].Length != rooms+1 {
+		t.Errorf("findAllPaths() found %d paths, want both corridors of length %d", len(paths), rooms+1)
+	}
+}
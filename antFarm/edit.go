@@ -0,0 +1,147 @@
+package antfarm
+
+import (
+	"context"
+
+	"test/models"
+)
+
+// AddRoom adds an ordinary room to the farm. A new room has no links yet, so
+// the current plan stays valid.
+func (af *AntFarm) AddRoom(name string, x, y int) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	room, err := af.newRoom(name, x, y, false, false)
+	if err != nil {
+		return err
+	}
+
+	af.planned()
+	return af.addRoom(room)
+}
+
+// RemoveRoom removes a room, its links and any events that mention it. Only
+// the planned path through the room, if any, is dropped and re-augmented, and
+// the other paths shortened where they can be.
+func (af *AntFarm) RemoveRoom(name string) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+	return af.deleteRoom(name)
+}
+
+// deleteRoom does the work of RemoveRoom for a caller holding the lock
+func (af *AntFarm) deleteRoom(name string) error {
+	room, exists := af.Rooms[name]
+	if !exists {
+		return &models.ParseError{Message: "nonexistent room"}
+	}
+	if room.IsStart || room.IsEnd {
+		return &models.ParseError{Message: "start and end rooms cannot be removed"}
+	}
+
+	p := af.planned()
+	inPlan := p.successor(room) != nil
+	if inPlan {
+		p.dropPath(af, room)
+	}
+
+	af.dropRoom(room)
+
+	if inPlan {
+		p.replan(af, true)
+	}
+	return nil
+}
+
+// AddLink connects two existing rooms and updates the current plan with any
+// new or shorter paths the tunnel opens up.
+func (af *AntFarm) AddLink(name1, name2 string) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	p := af.planned()
+	if err := af.addLink(name1, name2); err != nil {
+		return err
+	}
+
+	p.linked(af, af.Rooms[name1], af.Rooms[name2])
+	return nil
+}
+
+// RemoveLink disconnects two rooms. The plan is only touched when one of its
+// paths used the tunnel.
+func (af *AntFarm) RemoveLink(name1, name2 string) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+	return af.deleteLink(name1, name2)
+}
+
+// deleteLink does the work of RemoveLink for a caller holding the lock
+func (af *AntFarm) deleteLink(name1, name2 string) error {
+	room1, exists1 := af.Rooms[name1]
+	room2, exists2 := af.Rooms[name2]
+	if !exists1 || !exists2 {
+		return models.ErrUnknownRoom
+	}
+	if !hasRoom(room1.Connected, room2) {
+		return &models.ParseError{Message: "nonexistent link"}
+	}
+
+	p := af.planned()
+	inPlan := p.flow[arc{room1, room2}] || p.flow[arc{room2, room1}]
+	if inPlan {
+		delete(p.flow, arc{room1, room2})
+		delete(p.flow, arc{room2, room1})
+		for _, room := range []*models.Room{room1, room2} {
+			if !room.IsStart && !room.IsEnd {
+				p.dropPath(af, room)
+			}
+		}
+	}
+
+	room1.Connected = removeRoom(room1.Connected, room2)
+	room2.Connected = removeRoom(room2.Connected, room1)
+
+	if inPlan {
+		p.replan(af, true)
+	}
+	return nil
+}
+
+// planned returns the farm's incremental planner, creating it on first use
+func (af *AntFarm) planned() *planner {
+	if af.planner == nil {
+		af.planner = newPlanner(context.Background(), af)
+	}
+	return af.planner
+}
+
+// dropRoom unlinks a room and forgets it along with any events that mention
+// it, leaving the plan alone
+func (af *AntFarm) dropRoom(room *models.Room) {
+	for _, next := range room.Connected {
+		next.Connected = removeRoom(next.Connected, room)
+	}
+	room.Connected = nil
+	delete(af.Rooms, room.Name)
+
+	events := af.Events[:0]
+	for _, event := range af.Events {
+		if event.Room != room.Name && event.To != room.Name {
+			events = append(events, event)
+		}
+	}
+	af.Events = events
+}
+
+// removeRoom returns rooms without room, reusing the backing array
+func removeRoom(rooms []*models.Room, room *models.Room) []*models.Room {
+	kept := rooms[:0]
+	for _, r := range rooms {
+		if r != room {
+			kept = append(kept, r)
+		}
+	}
+	return kept
+}
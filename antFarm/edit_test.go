@@ -0,0 +1,277 @@
+package antfarm
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"test/generator"
+	"test/models"
+)
+
+func TestAntFarm_AddRoom(t *testing.T) {
+	testCases := []struct {
+		name    string
+		room    string
+		wantErr bool
+	}{
+		{"valid room", "e", false},
+		{"duplicate room", "a", true},
+		{"empty name", "", true},
+		{"name with dash", "e-f", true},
+		{"comment name", "#e", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, crossFarm)
+			err := af.AddRoom(tc.room, 5, 5)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("AddRoom() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && af.Rooms[tc.room] == nil {
+				t.Errorf("AddRoom() did not add room %s", tc.room)
+			}
+		})
+	}
+}
+
+func TestAntFarm_edits(t *testing.T) {
+	tests := []struct {
+		name  string
+		edit  func(af *AntFarm) error
+		want  string
+		fresh bool // whether the plan should be rebuilt
+	}{
+		{
+			name: "Link that adds a path",
+			edit: func(af *AntFarm) error {
+				if err := af.AddRoom("e", 1, 2); err != nil {
+					return err
+				}
+				if err := af.AddLink("s", "e"); err != nil {
+					return err
+				}
+				return af.AddLink("e", "t")
+			},
+			want:  "s-e-t s-a-d-t s-c-b-t",
+			fresh: true,
+		},
+		{
+			name:  "Shortcut beside a planned path",
+			edit:  func(af *AntFarm) error { return af.AddLink("c", "t") },
+			want:  "s-c-t s-a-d-t",
+			fresh: true,
+		},
+		{
+			name: "Shortcut between unplanned rooms",
+			edit: func(af *AntFarm) error {
+				if err := af.AddRoom("e", 1, 2); err != nil {
+					return err
+				}
+				if err := af.AddLink("s", "e"); err != nil {
+					return err
+				}
+				if err := af.AddLink("e", "t"); err != nil {
+					return err
+				}
+				return af.AddLink("e", "b")
+			},
+			want:  "s-e-t s-a-d-t s-c-b-t",
+			fresh: true,
+		},
+		{
+			name: "Link into a dead end",
+			edit: func(af *AntFarm) error {
+				if err := af.AddRoom("e", 1, 2); err != nil {
+					return err
+				}
+				return af.AddLink("a", "e")
+			},
+			want:  "s-a-d-t s-c-b-t",
+			fresh: false,
+		},
+		{
+			name:  "Link that adds nothing",
+			edit:  func(af *AntFarm) error { return af.AddLink("c", "d") },
+			want:  "s-a-d-t s-c-b-t",
+			fresh: false,
+		},
+		{
+			name:  "Unused link removed",
+			edit:  func(af *AntFarm) error { return af.RemoveLink("a", "b") },
+			want:  "s-a-d-t s-c-b-t",
+			fresh: false,
+		},
+		{
+			name:  "Planned link removed",
+			edit:  func(af *AntFarm) error { return af.RemoveLink("a", "d") },
+			want:  "s-c-b-t",
+			fresh: true,
+		},
+		{
+			name:  "Planned room removed",
+			edit:  func(af *AntFarm) error { return af.RemoveRoom("c") },
+			want:  "s-a-d-t",
+			fresh: true,
+		},
+		{
+			name:  "Start room cannot be removed",
+			edit:  func(af *AntFarm) error { return af.RemoveRoom("s") },
+			want:  "",
+			fresh: false,
+		},
+		{
+			name:  "Missing link cannot be removed",
+			edit:  func(af *AntFarm) error { return af.RemoveLink("s", "t") },
+			want:  "",
+			fresh: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := parseFarm(t, crossFarm)
+			before := af.planned().paths
+
+			err := tt.edit(af)
+			if (err != nil) != (tt.want == "") {
+				t.Fatalf("\nTest: %s\nunexpected error: %v", tt.name, err)
+			}
+			if err != nil {
+				return
+			}
+
+			got := strings.Join(pathNames(af.planner.paths), " ")
+			if got != tt.want {
+				t.Errorf("\nTest: %s\ngot = %v\nwant = %v", tt.name, got, tt.want)
+			}
+			rebuilt := len(before) != len(af.planner.paths) || &before[0] != &af.planner.paths[0]
+			if rebuilt != tt.fresh {
+				t.Errorf("\nTest: %s\nplan rebuilt = %v, want %v", tt.name, rebuilt, tt.fresh)
+			}
+
+			// The edited plan is as good as solving the edited farm from scratch
+			for numAnts := 1; numAnts <= 10; numAnts++ {
+				got, err := af.Turns(WithAnts(numAnts))
+				if err != nil {
+					t.Fatalf("Turns() error = %v", err)
+				}
+				want, err := parseFarm(t, farmText(af)).Turns(WithAnts(numAnts))
+				if err != nil {
+					t.Fatalf("Turns() of the fresh farm error = %v", err)
+				}
+				if got != want {
+					t.Errorf("Turns() for %d ants = %d, want %d as parsed afresh", numAnts, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestAntFarm_AddLink_generated(t *testing.T) {
+	for _, cfg := range []generator.Config{
+		{Preset: generator.FlowTen, Seed: 4},
+		{Preset: generator.GreedyTrap, Seed: 1},
+		{Preset: generator.Random, Seed: 2, Rooms: 120},
+	} {
+		t.Run(string(cfg.Preset), func(t *testing.T) {
+			af := parseFarm(t, generatedInput(t, cfg))
+			af.planned()
+			names := make([]string, 0, len(af.Rooms))
+			for name := range af.Rooms {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+
+			// Every tunnel added keeps the plan as short as one made afresh
+			r := rand.New(rand.NewSource(7))
+			for i := 0; i < 30; i++ {
+				a, b := names[r.Intn(len(names))], names[r.Intn(len(names))]
+				if err := af.AddLink(a, b); err != nil {
+					continue
+				}
+				want := newPlanner(context.Background(), af)
+				if len(af.planner.paths) != len(want.paths) || totalLength(af.planner.paths) != totalLength(want.paths) {
+					t.Fatalf("after linking %s-%s: %d paths of %d moves, want %d paths of %d moves as planned afresh",
+						a, b, len(af.planner.paths), totalLength(af.planner.paths), len(want.paths), totalLength(want.paths))
+				}
+			}
+		})
+	}
+}
+
+// totalLength adds up the moves of paths
+func totalLength(paths []models.Path) int {
+	total := 0
+	for _, path := range paths {
+		total += path.Length
+	}
+	return total
+}
+
+func TestAntFarm_RemoveRoom_events(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	af.Schedule(models.Event{Turn: 1, Kind: models.BlockRoom, Room: "c"})
+	af.Schedule(models.Event{Turn: 2, Kind: models.BlockRoom, Room: "d"})
+
+	if err := af.RemoveRoom("c"); err != nil {
+		t.Fatalf("RemoveRoom() error = %v", err)
+	}
+	if len(af.Events) != 1 || af.Events[0].Room != "d" {
+		t.Errorf("RemoveRoom() left events %v, want only the one for d", af.Events)
+	}
+	if hasRoom(af.Rooms["s"].Connected, af.Rooms["c"]) || af.Rooms["c"] != nil {
+		t.Errorf("RemoveRoom() left room c in the farm")
+	}
+}
+
+func TestAntFarm_SimulateMovement_afterEdit(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	if err := af.RemoveLink("a", "d"); err != nil {
+		t.Fatalf("RemoveLink() error = %v", err)
+	}
+
+	got, err := af.SimulateMovement()
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+	want := "L1-c\nL1-b L2-c\nL1-t L2-b L3-c\nL2-t L3-b\nL3-t\n"
+	if got != want {
+		t.Errorf("SimulateMovement() got =\n%v\nwant =\n%v", got, want)
+	}
+}
+
+// farmText writes the farm back out in the input format, rooms and tunnels
+// in name order
+func farmText(af *AntFarm) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, af.NumAnts)
+
+	names := make([]string, 0, len(af.Rooms))
+	for name := range af.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		room := af.Rooms[name]
+		switch {
+		case room.IsStart:
+			fmt.Fprintln(&b, "##start")
+		case room.IsEnd:
+			fmt.Fprintln(&b, "##end")
+		}
+		fmt.Fprintf(&b, "%s %d %d\n", room.Name, room.X, room.Y)
+	}
+	for _, name := range names {
+		for _, next := range af.Rooms[name].Connected {
+			if name < next.Name {
+				fmt.Fprintf(&b, "%s-%s\n", name, next.Name)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,384 @@
+package antfarm
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"test/models"
+)
+
+// link identifies a tunnel between two rooms regardless of direction
+type link struct {
+	a, b *models.Room
+}
+
+// newLink returns the key for the tunnel between two rooms
+func newLink(room1, room2 *models.Room) link {
+	if room1.Name > room2.Name {
+		room1, room2 = room2, room1
+	}
+	return link{room1, room2}
+}
+
+// obstacles tracks how scheduled events have changed the farm so far.
+// The rooms themselves are never modified.
+type obstacles struct {
+	blocked map[*models.Room]bool
+	closed  map[link]bool
+	opened  map[*models.Room][]*models.Room
+}
+
+func newObstacles() *obstacles {
+	return &obstacles{
+		blocked: make(map[*models.Room]bool),
+		closed:  make(map[link]bool),
+		opened:  make(map[*models.Room][]*models.Room),
+	}
+}
+
+// neighbors returns the rooms reachable from room through open tunnels
+func (o *obstacles) neighbors(room *models.Room) []*models.Room {
+	rooms := make([]*models.Room, 0, len(room.Connected)+len(o.opened[room]))
+	for _, next := range room.Connected {
+		if !o.closed[newLink(room, next)] {
+			rooms = append(rooms, next)
+		}
+	}
+	for _, next := range o.opened[room] {
+		if !o.closed[newLink(room, next)] {
+			rooms = append(rooms, next)
+		}
+	}
+	return rooms
+}
+
+// routeClear reports whether an ant can still follow rooms, starting from rooms[0]
+func (o *obstacles) routeClear(rooms []*models.Room) bool {
+	for i := 1; i < len(rooms); i++ {
+		if o.blocked[rooms[i]] || o.closed[newLink(rooms[i-1], rooms[i])] {
+			return false
+		}
+	}
+	return len(rooms) > 0
+}
+
+// apply records the effect of an event
+func (o *obstacles) apply(af *AntFarm, event models.Event) {
+	room := af.Rooms[event.Room]
+	switch event.Kind {
+	case models.BlockRoom:
+		o.blocked[room] = true
+	case models.UnblockRoom:
+		delete(o.blocked, room)
+	case models.CloseLink:
+		o.closed[newLink(room, af.Rooms[event.To])] = true
+	case models.OpenLink:
+		to := af.Rooms[event.To]
+		delete(o.closed, newLink(room, to))
+		if !hasRoom(room.Connected, to) && !hasRoom(o.opened[room], to) {
+			o.opened[room] = append(o.opened[room], to)
+			o.opened[to] = append(o.opened[to], room)
+		}
+	}
+}
+
+// hasRoom reports whether room is in rooms
+func hasRoom(rooms []*models.Room, room *models.Room) bool {
+	for _, r := range rooms {
+		if r == room {
+			return true
+		}
+	}
+	return false
+}
+
+// Schedule validates an event and adds it to the farm's event list
+func (af *AntFarm) Schedule(event models.Event) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+	return af.schedule(event)
+}
+
+// schedule validates and stores an event; the caller holds the lock
+func (af *AntFarm) schedule(event models.Event) error {
+	if event.Turn < 1 {
+		return &models.ParseError{Message: "event turn must be positive"}
+	}
+
+	room, exists := af.Rooms[event.Room]
+	if !exists {
+		return &models.ParseError{Message: "event references nonexistent room"}
+	}
+
+	switch event.Kind {
+	case models.BlockRoom, models.UnblockRoom:
+		if room.IsStart || room.IsEnd {
+			return &models.ParseError{Message: "start and end rooms cannot be blocked"}
+		}
+	case models.CloseLink, models.OpenLink:
+		if _, exists := af.Rooms[event.To]; !exists {
+			return &models.ParseError{Message: "event references nonexistent room"}
+		}
+		if event.Room == event.To {
+			return models.ErrInvalidLink
+		}
+	default:
+		return &models.ParseError{Message: "unknown event kind"}
+	}
+
+	af.Events = append(af.Events, event)
+	return nil
+}
+
+// ParseEvents reads a file of scheduled events and adds them to the farm.
+// Each line holds a turn, an action and a target, e.g. "5 block h" or
+// "8 open a-b". Blank lines and lines starting with '#' are ignored.
+func (af *AntFarm) ParseEvents(filename string) error {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return fmt.Errorf("opening file: %w", err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		event, err := parseEvent(line)
+		if err == nil {
+			err = af.schedule(event)
+		}
+		if err != nil {
+			return fmt.Errorf("parsing events, line %d: %w", lineNum, err)
+		}
+	}
+
+	return scanner.Err()
+}
+
+// parseEvent parses a single "<turn> <action> <target>" line
+func parseEvent(line string) (models.Event, error) {
+	parts := strings.Fields(line)
+	if len(parts) != 3 {
+		return models.Event{}, &models.ParseError{Message: "invalid event format"}
+	}
+
+	turn, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return models.Event{}, &models.ParseError{Message: "invalid event turn"}
+	}
+
+	event := models.Event{Turn: turn, Kind: -1, Room: parts[2]}
+	for kind, name := range models.EventKindNames {
+		if name == parts[1] {
+			event.Kind = models.EventKind(kind)
+		}
+	}
+
+	switch event.Kind {
+	case -1:
+		return models.Event{}, &models.ParseError{Message: "unknown event kind"}
+	case models.CloseLink, models.OpenLink:
+		rooms := strings.Split(parts[2], "-")
+		if len(rooms) != 2 {
+			return models.Event{}, models.ErrInvalidLink
+		}
+		event.Room, event.To = rooms[0], rooms[1]
+	}
+
+	return event, nil
+}
+
+// routeAround finds the shortest route from a room to the end room that avoids
+// blocked rooms and closed links. It returns nil if the end cannot be reached.
+// A room of a simplified farm is left only after waiting out the rooms it
+// stands for, so its weight counts towards the length of the route.
+func (af *AntFarm) routeAround(from *models.Room, obs *obstacles) []*models.Room {
+	type visit struct {
+		room *models.Room
+		wait int
+	}
+	prev := map[*models.Room]*models.Room{from: nil}
+	queue := []visit{{from, af.weights[from]}}
+
+	for len(queue) > 0 {
+		room, wait := queue[0].room, queue[0].wait
+		queue = queue[1:]
+		if wait > 0 {
+			queue = append(queue, visit{room, wait - 1})
+			continue
+		}
+
+		if room == af.End {
+			route := make([]*models.Room, 0)
+			for ; room != nil; room = prev[room] {
+				route = append(route, room)
+			}
+			for i, j := 0, len(route)-1; i < j; i, j = i+1, j-1 {
+				route[i], route[j] = route[j], route[i]
+			}
+			return route
+		}
+
+		for _, next := range obs.neighbors(room) {
+			if _, seen := prev[next]; seen || obs.blocked[next] {
+				continue
+			}
+			prev[next] = room
+			queue = append(queue, visit{next, af.weights[next]})
+		}
+	}
+
+	return nil
+}
+
+// reroute gives a new route to every ant whose remaining path is no longer
+// usable, logging each change to out.
+func (s *simulation) reroute(active []*models.Ant, obs *obstacles, turn int, out *strings.Builder) {
+	af := s.farm
+	for _, ant := range active {
+		if ant.Path != nil && obs.routeClear(ant.Path[ant.PathIndex:]) {
+			continue
+		}
+
+		route := af.routeAround(ant.CurrentRoom, obs)
+		if route == nil {
+			if ant.Path != nil {
+				fmt.Fprintf(out, "# turn %d: L%d has no route to %s\n", turn, ant.Id, af.End.Name)
+			}
+			ant.Path, ant.PathIndex = nil, 0
+			continue
+		}
+
+		names := make([]string, len(route)-1)
+		for i, room := range route[1:] {
+			names[i] = room.Name
+		}
+		fmt.Fprintf(out, "# turn %d: L%d rerouted via %s\n", turn, ant.Id, strings.Join(names, "-"))
+		ant.Path, ant.PathIndex = route, 0
+	}
+}
+
+// moveAnts moves every active ant that can advance this turn and returns the
+// moves in ant order. Rooms stay occupied until their ant leaves, each tunnel
+// is used at most once per turn, and ants are revisited until none can move
+// so that an ant held up by one that later moved still gets its turn. A nil
+// occupied lets rooms hold any number of ants, as EdgeDisjoint routing does.
+func moveAnts(active []*models.Ant, obs *obstacles, occupied map[*models.Room]*models.Ant) []string {
+	moved := make(map[*models.Ant]string)
+	used := make(map[link]bool)
+
+	for progress := true; progress; {
+		progress = false
+		for _, ant := range active {
+			if _, done := moved[ant]; done || ant.HasReached || ant.PathIndex >= len(ant.Path)-1 {
+				continue
+			}
+
+			nextRoom := ant.Path[ant.PathIndex+1]
+			tunnel := newLink(ant.CurrentRoom, nextRoom)
+			if obs.blocked[nextRoom] || obs.closed[tunnel] || used[tunnel] {
+				continue
+			}
+			if occupied[nextRoom] != nil && !nextRoom.IsEnd {
+				continue
+			}
+
+			delete(occupied, ant.CurrentRoom)
+			if occupied != nil && !nextRoom.IsStart && !nextRoom.IsEnd {
+				occupied[nextRoom] = ant
+			}
+			used[tunnel] = true
+
+			ant.CurrentRoom = nextRoom
+			ant.PathIndex++
+			ant.HasReached = nextRoom.IsEnd
+			moved[ant] = fmt.Sprintf("L%d-%s", ant.Id, nextRoom.Name)
+			progress = true
+		}
+	}
+
+	moves := make([]string, 0, len(moved))
+	for _, ant := range active {
+		if move, ok := moved[ant]; ok {
+			moves = append(moves, move)
+		}
+	}
+	return moves
+}
+
+// runWithEvents runs the simulation turn by turn, applying scheduled events
+// before each turn's moves. Event and reroute notes are written as comment
+// lines ahead of the moves of the turn they happen in.
+func (s *simulation) runWithEvents(w io.Writer, sol models.Solution) error {
+	af := s.farm
+	s.ants = newAnts(s.numAnts, af.Start)
+	for i, j := range sol.Dispatch().PathOf {
+		s.ants[i].Path = sol.Paths[j].Rooms
+	}
+
+	events := make([]models.Event, len(af.Events))
+	copy(events, af.Events)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Turn < events[j].Turn
+	})
+
+	obs := newObstacles()
+	var occupied map[*models.Room]*models.Ant
+	if s.routing == VertexDisjoint {
+		occupied = make(map[*models.Room]*models.Ant)
+	}
+	active := s.ants
+	pending := 0
+
+	for turn := 1; ; turn++ {
+		if err := s.interrupted(); err != nil {
+			return err
+		}
+		var out strings.Builder
+		if pending < len(events) && events[pending].Turn == turn {
+			for ; pending < len(events) && events[pending].Turn == turn; pending++ {
+				obs.apply(af, events[pending])
+				fmt.Fprintf(&out, "# %s\n", events[pending])
+			}
+			s.reroute(active, obs, turn, &out)
+		}
+
+		moves := moveAnts(active, obs, occupied)
+		if len(moves) > 0 {
+			out.WriteString(strings.Join(moves, " ") + "\n")
+		}
+		if _, err := io.WriteString(w, out.String()); err != nil {
+			return err
+		}
+
+		// Finished ants are dropped so later turns only visit the rest
+		remaining := active[:0:0]
+		for _, ant := range active {
+			if !ant.HasReached {
+				remaining = append(remaining, ant)
+			}
+		}
+		active = remaining
+
+		if len(active) == 0 {
+			s.turns = turn
+			return nil
+		}
+
+		if len(moves) == 0 && pending == len(events) {
+			return fmt.Errorf("ERROR: ants stranded after turn %d, no route to end", turn)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package antfarm
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"test/models"
+)
+
+// newEventFarm builds a farm with a short route through a and a longer one through b1 and b2:
+//
+//	start - a - end
+//	start - b1 - b2 - end
+func newEventFarm(numAnts int) *AntFarm {
+	createRoom := func(name string, isStart, isEnd bool) *models.Room {
+		return &models.Room{
+			Name:      name,
+			IsStart:   isStart,
+			IsEnd:     isEnd,
+			Connected: make([]*models.Room, 0),
+		}
+	}
+
+	af := NewAntFarm()
+	af.NumAnts = numAnts
+	af.Start = createRoom("start", true, false)
+	af.End = createRoom("end", false, true)
+	af.Rooms["start"] = af.Start
+	af.Rooms["end"] = af.End
+	for _, name := range []string{"a", "b1", "b2"} {
+		af.Rooms[name] = createRoom(name, false, false)
+	}
+	for _, l := range []string{"start-a", "a-end", "start-b1", "b1-b2", "b2-end"} {
+		if err := af.parseLink(l); err != nil {
+			panic(err)
+		}
+	}
+	af.initializeAnts()
+	return af
+}
+
+func TestAntFarm_Schedule(t *testing.T) {
+	testCases := []struct {
+		name    string
+		event   models.Event
+		wantErr bool
+	}{
+		{"block room", models.Event{Turn: 1, Kind: models.BlockRoom, Room: "a"}, false},
+		{"open new link", models.Event{Turn: 3, Kind: models.OpenLink, Room: "a", To: "b2"}, false},
+		{"close link", models.Event{Turn: 2, Kind: models.CloseLink, Room: "a", To: "end"}, false},
+		{"turn zero", models.Event{Turn: 0, Kind: models.BlockRoom, Room: "a"}, true},
+		{"nonexistent room", models.Event{Turn: 1, Kind: models.BlockRoom, Room: "x"}, true},
+		{"block end room", models.Event{Turn: 1, Kind: models.BlockRoom, Room: "end"}, true},
+		{"link to itself", models.Event{Turn: 1, Kind: models.OpenLink, Room: "a", To: "a"}, true},
+		{"link to nonexistent room", models.Event{Turn: 1, Kind: models.CloseLink, Room: "a", To: "x"}, true},
+		{"unknown kind", models.Event{Turn: 1, Kind: 42, Room: "a"}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := newEventFarm(1)
+			err := af.Schedule(tc.event)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("Schedule() error = %v, wantErr %v", err, tc.wantErr)
+			}
+			if !tc.wantErr && len(af.Events) != 1 {
+				t.Errorf("Schedule() stored %d events, want 1", len(af.Events))
+			}
+		})
+	}
+}
+
+func TestParseEvent(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		want    models.Event
+		wantErr bool
+	}{
+		{"block room", "5 block a", models.Event{Turn: 5, Kind: models.BlockRoom, Room: "a"}, false},
+		{"open link", "8 open a-b2", models.Event{Turn: 8, Kind: models.OpenLink, Room: "a", To: "b2"}, false},
+		{"missing target", "5 block", models.Event{}, true},
+		{"invalid turn", "x block a", models.Event{}, true},
+		{"unknown action", "5 flood a", models.Event{}, true},
+		{"invalid link", "5 close a-b-c", models.Event{}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseEvent(tc.input)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("parseEvent() error = %v, wantErr %v", err, tc.wantErr)
+				return
+			}
+			if got != tc.want {
+				t.Errorf("parseEvent() got = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAntFarm_ParseEvents(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.txt")
+	invalid := filepath.Join(dir, "invalid.txt")
+	os.WriteFile(valid, []byte("# collapse\n2 block a\n\n4 unblock a\n"), 0o644)
+	os.WriteFile(invalid, []byte("2 block a\n3 block nowhere\n"), 0o644)
+
+	af := newEventFarm(1)
+	if err := af.ParseEvents(valid); err != nil {
+		t.Fatalf("ParseEvents() error = %v", err)
+	}
+	if len(af.Events) != 2 {
+		t.Errorf("ParseEvents() stored %d events, want 2", len(af.Events))
+	}
+
+	err := newEventFarm(1).ParseEvents(invalid)
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("ParseEvents() error = %v, want error on line 2", err)
+	}
+}
+
+func TestAntFarm_simulateWithEvents(t *testing.T) {
+	tests := []struct {
+		name    string
+		numAnts int
+		events  []models.Event
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "Blocked room reroutes waiting ants",
+			numAnts: 2,
+			events:  []models.Event{{Turn: 1, Kind: models.BlockRoom, Room: "a"}},
+			want: "# turn 1: block a\n" +
+				"# turn 1: L1 rerouted via b1-b2-end\n" +
+				"# turn 1: L2 rerouted via b1-b2-end\n" +
+				"L1-b1\nL1-b2 L2-b1\nL1-end L2-b2\nL2-end\n",
+		},
+		{
+			name:    "Ant past the collapse keeps going",
+			numAnts: 1,
+			events:  []models.Event{{Turn: 2, Kind: models.BlockRoom, Room: "a"}},
+			want:    "L1-a\n# turn 2: block a\nL1-end\n",
+		},
+		{
+			name:    "Closed tunnel strands ants",
+			numAnts: 1,
+			events: []models.Event{
+				{Turn: 1, Kind: models.CloseLink, Room: "a", To: "end"},
+				{Turn: 1, Kind: models.CloseLink, Room: "b2", To: "end"},
+			},
+			wantErr: true,
+		},
+		{
+			name:    "Opened tunnel frees stranded ant",
+			numAnts: 1,
+			events: []models.Event{
+				{Turn: 1, Kind: models.CloseLink, Room: "a", To: "end"},
+				{Turn: 1, Kind: models.CloseLink, Room: "b2", To: "end"},
+				{Turn: 3, Kind: models.OpenLink, Room: "b1", To: "end"},
+			},
+			want: "# turn 1: close a-end\n# turn 1: close b2-end\n" +
+				"# turn 1: L1 has no route to end\n" +
+				"# turn 3: open b1-end\n" +
+				"# turn 3: L1 rerouted via b1-end\n" +
+				"L1-b1\nL1-end\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			af := newEventFarm(tt.numAnts)
+			for _, event := range tt.events {
+				if err := af.Schedule(event); err != nil {
+					t.Fatalf("Schedule() error = %v", err)
+				}
+			}
+
+			got, err := af.SimulateMovement()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("\nTest: %s\nExpected error: %v, got: %v", tt.name, tt.wantErr, err)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("\nTest: %s\ngot =\n%v\nwant =\n%v", tt.name, got, tt.want)
+			}
+		})
+	}
+}
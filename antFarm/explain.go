@@ -0,0 +1,127 @@
+package antfarm
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"test/models"
+)
+
+// Explain writes how a run's routing is chosen: the candidate paths the
+// search found and why each was kept or rejected, the ants and finishing turn
+// of every chosen path, and the rooms, or tunnels under EdgeDisjoint routing,
+// that blocked the most candidates. It returns the solution it explains,
+// which WriteSolution plays out without searching again.
+// Candidates are only listed for the default search; other path finders and
+// edited farms just report the paths they chose.
+func (af *AntFarm) Explain(w io.Writer, opts ...Option) (models.Solution, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	sim, err := af.newSimulation(opts)
+	if err != nil {
+		return models.Solution{}, err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	switch {
+	case sim.finder != nil:
+		fmt.Fprintf(tw, "paths found by %s\n", sim.finder.Name())
+	case af.planner != nil && sim.routing == VertexDisjoint && !sim.simplify:
+		fmt.Fprintln(tw, "paths kept by the planner across edits")
+	default:
+		sim.explain = func(candidates []models.Path, sel pathSelection) {
+			explainCandidates(tw, candidates, sel, sim.routing)
+		}
+	}
+
+	sol, err := sim.solve()
+	if err != nil {
+		tw.Flush()
+		return models.Solution{}, err
+	}
+
+	fmt.Fprintf(tw, "chosen paths for %s (%s):\n", plural(sim.numAnts, "ant"), sim.assigner.Name())
+	for j, path := range sol.Paths {
+		count := sol.Counts[j]
+		if count == 0 {
+			fmt.Fprintf(tw, "  %s\tlength %d\tunused\n", routeName(path), path.Length)
+			continue
+		}
+		fmt.Fprintf(tw, "  %s\tlength %d\t%s\tlast arrives turn %d\n",
+			routeName(path), path.Length, plural(count, "ant"), path.Length+count-1)
+	}
+	fmt.Fprintf(tw, "predicted turns: %d\n", sol.Turns)
+	return sol, tw.Flush()
+}
+
+// explainCandidates lists the candidates with the reason each was kept or
+// rejected by the selection, then the rooms or tunnels behind the rejections
+func explainCandidates(w io.Writer, candidates []models.Path, sel pathSelection, routing Routing) {
+	if len(candidates) == 0 {
+		fmt.Fprintln(w, "candidate paths: none found")
+		return
+	}
+	fmt.Fprintf(w, "candidate paths: %d found, best combination seeded with #%d\n", len(candidates), sel.seed+1)
+
+	kept := make(map[int]bool, len(sel.kept))
+	for _, k := range sel.kept {
+		kept[k] = true
+	}
+	rejected := sel.rejected(candidates, routing)
+	blocked := make(map[string]int)
+	for i, path := range candidates {
+		reason := "not compared before the run stopped"
+		if o, isRejected := rejected[i]; isRejected {
+			reason = fmt.Sprintf("rejected: shares %s with #%d", o.shared, o.path+1)
+			blocked[o.shared]++
+		} else if kept[i] {
+			reason = "kept"
+		}
+		fmt.Fprintf(w, "  #%d\t%s\tlength %d\t%s\n", i+1, routeName(path), path.Length, reason)
+	}
+
+	names := make([]string, 0, len(blocked))
+	for name := range blocked {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if blocked[names[i]] != blocked[names[j]] {
+			return blocked[names[i]] > blocked[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	heading := "bottleneck rooms"
+	if routing == EdgeDisjoint {
+		heading = "bottleneck tunnels"
+	}
+	if len(names) == 0 {
+		fmt.Fprintf(w, "%s: none\n", heading)
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", heading)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %s\tblocked %s\n", name, plural(blocked[name], "candidate"))
+	}
+}
+
+// plural formats a count of things, e.g. "1 ant" or "3 ants"
+func plural(n int, thing string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, thing)
+	}
+	return fmt.Sprintf("%d %ss", n, thing)
+}
+
+// routeName renders a path as "start-a-end"
+func routeName(path models.Path) string {
+	names := make([]string, len(path.Rooms))
+	for i, room := range path.Rooms {
+		names[i] = room.Name
+	}
+	return strings.Join(names, "-")
+}
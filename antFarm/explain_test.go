@@ -0,0 +1,129 @@
+package antfarm
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAntFarm_Explain(t *testing.T) {
+	testCases := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{
+			name: "default search",
+			want: "candidate paths: 4 found, best combination seeded with #2\n" +
+				"  #1  s-a-b-t      length 3  rejected: shares a with #2\n" +
+				"  #2  s-a-d-t      length 3  kept\n" +
+				"  #3  s-c-b-t      length 3  kept\n" +
+				"  #4  s-c-b-a-d-t  length 5  rejected: shares a with #2\n" +
+				"bottleneck rooms:\n" +
+				"  a  blocked 2 candidates\n" +
+				"chosen paths for 3 ants (greedy):\n" +
+				"  s-a-d-t  length 3  2 ants  last arrives turn 4\n" +
+				"  s-c-b-t  length 3  1 ant   last arrives turn 3\n" +
+				"predicted turns: 4\n",
+		},
+		{
+			name: "unused path",
+			opts: []Option{WithAnts(1), WithAssigner(Exact)},
+			want: "candidate paths: 4 found, best combination seeded with #2\n" +
+				"  #1  s-a-b-t      length 3  rejected: shares a with #2\n" +
+				"  #2  s-a-d-t      length 3  kept\n" +
+				"  #3  s-c-b-t      length 3  kept\n" +
+				"  #4  s-c-b-a-d-t  length 5  rejected: shares a with #2\n" +
+				"bottleneck rooms:\n" +
+				"  a  blocked 2 candidates\n" +
+				"chosen paths for 1 ant (exact):\n" +
+				"  s-a-d-t  length 3  1 ant  last arrives turn 3\n" +
+				"  s-c-b-t  length 3  unused\n" +
+				"predicted turns: 3\n",
+		},
+		{
+			name: "edge routing",
+			opts: []Option{WithRouting(EdgeDisjoint)},
+			want: "candidate paths: 4 found, best combination seeded with #2\n" +
+				"  #1  s-a-b-t      length 3  rejected: shares a-s with #2\n" +
+				"  #2  s-a-d-t      length 3  kept\n" +
+				"  #3  s-c-b-t      length 3  kept\n" +
+				"  #4  s-c-b-a-d-t  length 5  rejected: shares a-d with #2\n" +
+				"bottleneck tunnels:\n" +
+				"  a-d  blocked 1 candidate\n" +
+				"  a-s  blocked 1 candidate\n" +
+				"chosen paths for 3 ants (greedy):\n" +
+				"  s-a-d-t  length 3  2 ants  last arrives turn 4\n" +
+				"  s-c-b-t  length 3  1 ant   last arrives turn 3\n" +
+				"predicted turns: 4\n",
+		},
+		{
+			name: "simplified",
+			opts: []Option{WithSimplify()},
+			want: "candidate paths: 4 found, best combination seeded with #2\n" +
+				"  #1  s-a-b-t      length 3  rejected: shares a with #2\n" +
+				"  #2  s-a-d-t      length 3  kept\n" +
+				"  #3  s-c-b-t      length 3  kept\n" +
+				"  #4  s-c-b-a-d-t  length 5  rejected: shares a with #2\n" +
+				"bottleneck rooms:\n" +
+				"  a  blocked 2 candidates\n" +
+				"chosen paths for 3 ants (greedy):\n" +
+				"  s-a-d-t  length 3  2 ants  last arrives turn 4\n" +
+				"  s-c-b-t  length 3  1 ant   last arrives turn 3\n" +
+				"predicted turns: 4\n",
+		},
+		{
+			name: "other finder",
+			opts: []Option{WithPathFinder(BFSShortest)},
+			want: "paths found by bfs-shortest\n" +
+				"chosen paths for 3 ants (greedy):\n" +
+				"  s-a-b-t  length 3  3 ants  last arrives turn 5\n" +
+				"predicted turns: 5\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, crossFarm)
+			var out strings.Builder
+			sol, err := af.Explain(&out, tc.opts...)
+			if err != nil {
+				t.Fatalf("Explain() error = %v", err)
+			}
+			if out.String() != tc.want {
+				t.Errorf("Explain() =\n%s\nwant\n%s", out.String(), tc.want)
+			}
+			if want, err := af.Solve(tc.opts...); err != nil || !reflect.DeepEqual(sol, want) {
+				t.Errorf("Explain() solution = %v, want %v as Solve() finds, error %v", sol, want, err)
+			}
+		})
+	}
+}
+
+func TestAntFarm_Explain_context(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	af.Workers = 1
+
+	// The context ends once the search and the combination seeded with #1
+	// have looked at it, so the listing is the one the run settles for
+	ctx := &countdownContext{Context: context.Background(), n: 5}
+	var out strings.Builder
+	if _, err := af.Explain(&out, WithContext(ctx), WithBestSoFar()); err != nil {
+		t.Fatalf("Explain() error = %v", err)
+	}
+	want := "candidate paths: 4 found, best combination seeded with #1\n" +
+		"  #1  s-a-b-t      length 3  kept\n" +
+		"  #2  s-a-d-t      length 3  rejected: shares a with #1\n" +
+		"  #3  s-c-b-t      length 3  rejected: shares b with #1\n" +
+		"  #4  s-c-b-a-d-t  length 5  rejected: shares b with #1\n" +
+		"bottleneck rooms:\n" +
+		"  b  blocked 2 candidates\n" +
+		"  a  blocked 1 candidate\n" +
+		"chosen paths for 3 ants (greedy):\n" +
+		"  s-a-b-t  length 3  3 ants  last arrives turn 5\n" +
+		"predicted turns: 5\n"
+	if out.String() != want {
+		t.Errorf("Explain() =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,342 @@
+package antfarm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"test/models"
+)
+
+// PathFinder chooses the disjoint paths from start to end that the ants are
+// sent down
+type PathFinder interface {
+	// Name identifies the finder, as accepted by LookupPathFinder
+	Name() string
+	// FindPaths returns paths sorted by length for numAnts ants, no two of
+	// them sharing what routing forbids. When ctx ends it returns the best
+	// paths found by then, if any.
+	FindPaths(ctx context.Context, af *AntFarm, numAnts int, routing Routing) []models.Path
+}
+
+var (
+	// DFSGreedy enumerates every simple path and keeps the largest set of
+	// non-overlapping ones found by seeding with each path in turn
+	DFSGreedy PathFinder = dfsGreedy{}
+	// BFSShortest repeatedly takes the shortest path left once the rooms, or
+	// tunnels under EdgeDisjoint routing, of the paths already taken are
+	// removed
+	BFSShortest PathFinder = bfsShortest{}
+	// MaxFlow finds as many disjoint paths as the farm allows and uses the
+	// shortest of them worth taking
+	MaxFlow PathFinder = maxFlow{}
+	// KShortestDisjoint finds, for every k, the k disjoint paths of least total
+	// length and keeps the k that finishes soonest
+	KShortestDisjoint PathFinder = kShortestDisjoint{}
+)
+
+// sweeper is a PathFinder that can search once for any number of ants. The
+// function sweep returns gives the paths FindPaths would for each count.
+type sweeper interface {
+	sweep(ctx context.Context, af *AntFarm, routing Routing) func(numAnts int) []models.Path
+}
+
+// pathsByAnts searches with finder and returns the paths it chooses for any
+// number of ants. Finders that cannot search once for every count search
+// again for each.
+func pathsByAnts(ctx context.Context, finder PathFinder, af *AntFarm, routing Routing) func(numAnts int) []models.Path {
+	if s, ok := finder.(sweeper); ok {
+		return s.sweep(ctx, af, routing)
+	}
+	return func(numAnts int) []models.Path {
+		return finder.FindPaths(ctx, af, numAnts, routing)
+	}
+}
+
+// samePaths returns paths for any number of ants
+func samePaths(paths []models.Path) func(numAnts int) []models.Path {
+	return func(int) []models.Path { return paths }
+}
+
+// PathFinders lists every path finder, the default first
+var PathFinders = []PathFinder{DFSGreedy, BFSShortest, MaxFlow, KShortestDisjoint}
+
+// LookupPathFinder returns the path finder with the given name
+func LookupPathFinder(name string) (PathFinder, error) {
+	for _, finder := range PathFinders {
+		if finder.Name() == name {
+			return finder, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown path finder %q", name)
+}
+
+type dfsGreedy struct{}
+
+func (dfsGreedy) Name() string { return "dfs-greedy" }
+
+func (dfsGreedy) FindPaths(ctx context.Context, af *AntFarm, numAnts int, routing Routing) []models.Path {
+	return af.searchPaths(ctx, routing)
+}
+
+func (f dfsGreedy) sweep(ctx context.Context, af *AntFarm, routing Routing) func(numAnts int) []models.Path {
+	return samePaths(f.FindPaths(ctx, af, 0, routing))
+}
+
+type bfsShortest struct{}
+
+func (bfsShortest) Name() string { return "bfs-shortest" }
+
+func (bfsShortest) FindPaths(ctx context.Context, af *AntFarm, numAnts int, routing Routing) []models.Path {
+	paths := make([]models.Path, 0)
+	if af.Start == nil || af.End == nil {
+		return paths
+	}
+
+	obs := newObstacles()
+	for ctx.Err() == nil {
+		route := af.routeAround(af.Start, obs)
+		if route == nil {
+			return paths
+		}
+		paths = append(paths, models.Path{Rooms: route, Length: af.pathLength(route)})
+
+		if routing == EdgeDisjoint {
+			for i := 1; i < len(route); i++ {
+				obs.closed[newLink(route[i-1], route[i])] = true
+			}
+			continue
+		}
+		for _, room := range route[1 : len(route)-1] {
+			obs.blocked[room] = true
+		}
+		if len(route) == 2 {
+			obs.closed[newLink(af.Start, af.End)] = true
+		}
+	}
+	return paths
+}
+
+func (f bfsShortest) sweep(ctx context.Context, af *AntFarm, routing Routing) func(numAnts int) []models.Path {
+	return samePaths(f.FindPaths(ctx, af, 0, routing))
+}
+
+type maxFlow struct{}
+
+func (maxFlow) Name() string { return "max-flow" }
+
+func (f maxFlow) FindPaths(ctx context.Context, af *AntFarm, numAnts int, routing Routing) []models.Path {
+	return f.sweep(ctx, af, routing)(numAnts)
+}
+
+// sweep reuses the farm's planner when edits have created one. The planner
+// only routes vertex-disjoint paths, so EdgeDisjoint runs saturate a flow
+// network of unit tunnels instead and keep the best of its paths.
+func (maxFlow) sweep(ctx context.Context, af *AntFarm, routing Routing) func(numAnts int) []models.Path {
+	if routing == EdgeDisjoint {
+		if af.Start == nil || af.End == nil {
+			return samePaths(make([]models.Path, 0))
+		}
+		g := newFlowGraph(af, routing)
+		for ctx.Err() == nil && g.augment() {
+		}
+		paths := g.paths()
+		lengths := pathLengths(paths)
+		return func(numAnts int) []models.Path {
+			k, _ := bestPrefix(lengths, numAnts)
+			return paths[:k]
+		}
+	}
+
+	p := af.planner
+	if p == nil {
+		p = newPlanner(ctx, af)
+	}
+	return p.best
+}
+
+type kShortestDisjoint struct{}
+
+func (kShortestDisjoint) Name() string { return "k-shortest" }
+
+func (f kShortestDisjoint) FindPaths(ctx context.Context, af *AntFarm, numAnts int, routing Routing) []models.Path {
+	return f.sweep(ctx, af, routing)(numAnts)
+}
+
+// sweep sends one unit of flow at a time along the cheapest augmenting path,
+// so after k rounds the flow is the k disjoint paths of least total length.
+// Every round is kept, and each number of ants gets the round that finishes
+// soonest for it.
+func (kShortestDisjoint) sweep(ctx context.Context, af *AntFarm, routing Routing) func(numAnts int) []models.Path {
+	if af.Start == nil || af.End == nil {
+		return samePaths(make([]models.Path, 0))
+	}
+
+	g := newFlowGraph(af, routing)
+	var rounds [][]models.Path
+	for ctx.Err() == nil && g.augment() {
+		rounds = append(rounds, g.paths())
+	}
+	return func(numAnts int) []models.Path {
+		best := make([]models.Path, 0)
+		bestTurns := 0
+		for _, paths := range rounds {
+			k, turns := bestPrefix(pathLengths(paths), numAnts)
+			if len(best) == 0 || turns < bestTurns {
+				best, bestTurns = paths[:k], turns
+			}
+		}
+		return best
+	}
+}
+
+// flowEdge is an arc of the residual network. Its reverse arc is at index
+// rev in the adjacency list of to.
+type flowEdge struct {
+	to, rev         int
+	cap, flow, cost int
+}
+
+// flowGraph is the farm with every room split into an entry node 2i and an
+// exit node 2i+1 joined by a unit arc, so flows are vertex-disjoint paths.
+// The arc costs the rooms a simplified room stands in for. Under
+// EdgeDisjoint routing the arc takes as many units as the room has tunnels,
+// so only the unit tunnels keep the flows apart.
+type flowGraph struct {
+	farm   *AntFarm
+	rooms  []*models.Room
+	edges  [][]flowEdge
+	source int
+	sink   int
+}
+
+// newFlowGraph builds the split network of the farm for routing with rooms
+// numbered in name order, so results do not depend on map iteration
+func newFlowGraph(af *AntFarm, routing Routing) *flowGraph {
+	rooms := make([]*models.Room, 0, len(af.Rooms))
+	for _, room := range af.Rooms {
+		rooms = append(rooms, room)
+	}
+	sort.Slice(rooms, func(i, j int) bool {
+		return rooms[i].Name < rooms[j].Name
+	})
+	index := make(map[*models.Room]int, len(rooms))
+	for i, room := range rooms {
+		index[room] = i
+	}
+
+	g := &flowGraph{
+		farm:   af,
+		rooms:  rooms,
+		edges:  make([][]flowEdge, 2*len(rooms)),
+		source: 2*index[af.Start] + 1,
+		sink:   2 * index[af.End],
+	}
+	for i, room := range rooms {
+		capacity := 1
+		if routing == EdgeDisjoint {
+			capacity = max(len(room.Connected), 1)
+		}
+		g.addEdge(2*i, 2*i+1, capacity, af.weights[room])
+	}
+	for i, room := range rooms {
+		if room == af.End {
+			continue
+		}
+		for _, next := range room.Connected {
+			if next != af.Start {
+				g.addEdge(2*i+1, 2*index[next], 1, 1)
+			}
+		}
+	}
+	return g
+}
+
+func (g *flowGraph) addEdge(from, to, cap, cost int) {
+	g.edges[from] = append(g.edges[from], flowEdge{to: to, rev: len(g.edges[to]), cap: cap, cost: cost})
+	g.edges[to] = append(g.edges[to], flowEdge{to: from, rev: len(g.edges[from]) - 1, cost: -cost})
+}
+
+// augment pushes one unit along the cheapest path in the residual network
+// and reports whether there was one. Residual arcs can have negative cost,
+// so distances come from Bellman-Ford rather than a plain search.
+func (g *flowGraph) augment() bool {
+	type step struct{ node, edge int }
+	const unreached = -1
+
+	dist := make([]int, len(g.edges))
+	prev := make([]step, len(g.edges))
+	inQueue := make([]bool, len(g.edges))
+	for i := range dist {
+		dist[i] = unreached
+	}
+	dist[g.source] = 0
+	queue := []int{g.source}
+	inQueue[g.source] = true
+
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		inQueue[node] = false
+
+		for i, e := range g.edges[node] {
+			if e.flow >= e.cap {
+				continue
+			}
+			if d := dist[node] + e.cost; dist[e.to] == unreached || d < dist[e.to] {
+				dist[e.to] = d
+				prev[e.to] = step{node, i}
+				if !inQueue[e.to] {
+					queue = append(queue, e.to)
+					inQueue[e.to] = true
+				}
+			}
+		}
+	}
+	if dist[g.sink] == unreached {
+		return false
+	}
+
+	for node := g.sink; node != g.source; node = prev[node].node {
+		e := &g.edges[prev[node].node][prev[node].edge]
+		e.flow++
+		g.edges[e.to][e.rev].flow--
+	}
+	return true
+}
+
+// paths decomposes the current flow into paths sorted by length. Each unit
+// on a tunnel is followed once, since rooms may pass on several units.
+func (g *flowGraph) paths() []models.Path {
+	type arc struct{ node, edge int }
+	paths := make([]models.Path, 0)
+	start, end := g.rooms[g.source/2], g.rooms[g.sink/2]
+	followed := make(map[arc]int)
+
+	for _, first := range g.edges[g.source] {
+		if first.flow <= 0 || first.cost == 0 {
+			continue
+		}
+		rooms := []*models.Room{start}
+		for node := first.to; ; {
+			room := g.rooms[node/2]
+			rooms = append(rooms, room)
+			if room == end {
+				break
+			}
+			for i, e := range g.edges[node+1] {
+				if a := (arc{node + 1, i}); e.flow > followed[a] && e.cost > 0 {
+					followed[a]++
+					node = e.to
+					break
+				}
+			}
+		}
+		paths = append(paths, models.Path{Rooms: rooms, Length: g.farm.pathLength(rooms)})
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return paths[i].Length < paths[j].Length
+	})
+	return paths
+}
@@ -0,0 +1,82 @@
+package antfarm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+// directFarm links the start to the end both directly and through a room
+const directFarm = `4
+##start
+s 0 0
+a 1 1
+##end
+t 2 0
+s-a
+a-t
+s-t
+`
+
+func TestPathFinders(t *testing.T) {
+	testCases := []struct {
+		name    string
+		finder  PathFinder
+		input   string
+		numAnts int
+		want    []string
+	}{
+		{"dfs-greedy", DFSGreedy, crossFarm, 3, []string{"s-a-d-t", "s-c-b-t"}},
+		{"bfs-shortest takes the blocking path", BFSShortest, crossFarm, 3, []string{"s-a-b-t"}},
+		{"bfs-shortest direct link", BFSShortest, directFarm, 4, []string{"s-t", "s-a-t"}},
+		{"max-flow", MaxFlow, crossFarm, 3, []string{"s-a-d-t", "s-c-b-t"}},
+		{"max-flow one ant", MaxFlow, crossFarm, 1, []string{"s-a-d-t"}},
+		{"k-shortest", KShortestDisjoint, crossFarm, 3, []string{"s-a-d-t", "s-c-b-t"}},
+		{"k-shortest one ant", KShortestDisjoint, crossFarm, 1, []string{"s-a-b-t"}},
+		{"k-shortest direct link", KShortestDisjoint, directFarm, 4, []string{"s-t", "s-a-t"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, tc.input)
+			if got := pathNames(tc.finder.FindPaths(context.Background(), af, tc.numAnts, VertexDisjoint)); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("%s.FindPaths() = %v, want %v", tc.finder.Name(), got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPathFinders_noPath(t *testing.T) {
+	af := parseFarm(t, "2\n##start\ns 0 0\n##end\nt 1 0\n")
+	for _, finder := range PathFinders {
+		if got := finder.FindPaths(context.Background(), af, 2, VertexDisjoint); len(got) != 0 {
+			t.Errorf("%s.FindPaths() = %v, want no paths", finder.Name(), pathNames(got))
+		}
+		if _, err := af.Turns(WithPathFinder(finder)); err == nil {
+			t.Errorf("Turns(WithPathFinder(%s)) succeeded without a path", finder.Name())
+		}
+	}
+}
+
+func TestLookupPathFinder(t *testing.T) {
+	for _, finder := range PathFinders {
+		got, err := LookupPathFinder(finder.Name())
+		if err != nil || got != finder {
+			t.Errorf("LookupPathFinder(%q) = %v, %v", finder.Name(), got, err)
+		}
+	}
+	if _, err := LookupPathFinder("astar"); err == nil {
+		t.Errorf("LookupPathFinder() accepted an unknown name")
+	}
+}
+
+func TestAntFarm_Turns_pathFinder(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	want := map[PathFinder]int{DFSGreedy: 4, BFSShortest: 5, MaxFlow: 4, KShortestDisjoint: 4}
+	for finder, turns := range want {
+		got, err := af.Turns(WithPathFinder(finder))
+		if err != nil || got != turns {
+			t.Errorf("Turns(WithPathFinder(%s)) = %d, %v, want %d", finder.Name(), got, err, turns)
+		}
+	}
+}
@@ -11,13 +11,19 @@ func NewAntFarm() *AntFarm {
 
 // initializeAnts creates all ants in the start room
 func (af *AntFarm) initializeAnts() {
-	af.Ants = make([]*models.Ant, af.NumAnts)
-	for i := 0; i < af.NumAnts; i++ {
-		af.Ants[i] = &models.Ant{
+	af.Ants = newAnts(af.NumAnts, af.Start)
+}
+
+// newAnts creates numAnts ants waiting in the start room
+func newAnts(numAnts int, start *models.Room) []*models.Ant {
+	ants := make([]*models.Ant, numAnts)
+	for i := 0; i < numAnts; i++ {
+		ants[i] = &models.Ant{
 			Id:          i + 1,
-			CurrentRoom: af.Start,
+			CurrentRoom: start,
 			PathIndex:   0,
 			HasReached:  false,
 		}
 	}
+	return ants
 }
@@ -0,0 +1,312 @@
+package antfarm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"test/models"
+)
+
+// sourceLines remembers the input line that defined each room and tunnel
+type sourceLines struct {
+	rooms map[*models.Room]int
+	links map[link]int
+}
+
+func (s *sourceLines) setRoom(room *models.Room, line int) {
+	if s.rooms == nil {
+		s.rooms = make(map[*models.Room]int)
+	}
+	s.rooms[room] = line
+}
+
+func (s *sourceLines) setLink(l link, line int) {
+	if s.links == nil {
+		s.links = make(map[link]int)
+	}
+	s.links[l] = line
+}
+
+// FindingKind is the kind of problem Lint reports
+type FindingKind int
+
+const (
+	// NoRoute means the end cannot be reached from the start at all
+	NoRoute FindingKind = iota
+	// Disconnected rooms cannot be reached from the start
+	Disconnected
+	// DeadEnd rooms are reachable but lie on no path from start to end
+	DeadEnd
+	// UselessLink tunnels lie on no path from start to end
+	UselessLink
+)
+
+// FindingKindNames holds the name of each finding kind
+var FindingKindNames = [...]string{"no route", "disconnected", "dead end", "useless link"}
+
+func (k FindingKind) String() string {
+	if k < 0 || int(k) >= len(FindingKindNames) {
+		return fmt.Sprintf("FindingKind(%d)", int(k))
+	}
+	return FindingKindNames[k]
+}
+
+// Finding is a problem Lint found in the farm
+type Finding struct {
+	Line    int // input line of the room or tunnel concerned, zero if not parsed
+	Kind    FindingKind
+	Message string
+}
+
+func (f Finding) String() string {
+	return fmt.Sprintf("line %d: %s: %s", f.Line, f.Kind, f.Message)
+}
+
+// lintReport is what lint found, kept apart so Prune can act on it
+type lintReport struct {
+	disconnected []*models.Room // not counting the end's rooms when there is no route
+	deadEnds     []*models.Room
+	useless      []link
+	findings     []Finding
+}
+
+// Lint reports rooms that cannot be reached from the start, dead-end rooms
+// and tunnels that can never be part of a path from start to end, ordered by
+// input line. None of them can help the ants, and they slow the search down.
+func (af *AntFarm) Lint() []Finding {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+	return af.lint().findings
+}
+
+// Prune removes what Lint reports, so the search only sees rooms and tunnels
+// that can carry ants. It returns the number of rooms and tunnels removed.
+// Nothing pruned lies on a path from start to end, so any plan stays valid.
+func (af *AntFarm) Prune() (rooms, links int) {
+	af.mu.Lock()
+	defer af.mu.Unlock()
+
+	report := af.lint()
+	for _, l := range report.useless {
+		l.a.Connected = removeRoom(l.a.Connected, l.b)
+		l.b.Connected = removeRoom(l.b.Connected, l.a)
+		links++
+	}
+	for _, room := range append(report.disconnected, report.deadEnds...) {
+		links += len(room.Connected)
+		af.dropRoom(room)
+		rooms++
+	}
+	return rooms, links
+}
+
+// lint finds the farm's problems. A room or tunnel lies on some simple path
+// from start to end exactly when it shares a biconnected component with an
+// extra tunnel joining the start to the end, so one pass of Tarjan's
+// algorithm over the start's component sorts the useful from the useless.
+func (af *AntFarm) lint() lintReport {
+	var report lintReport
+	if af.Start == nil || af.End == nil {
+		return report
+	}
+
+	reached := make(map[*models.Room]bool)
+	af.component(af.Start, reached)
+
+	// Rooms the start cannot reach, one finding per connected group
+	names := make([]string, 0, len(af.Rooms))
+	for name := range af.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	grouped := make(map[*models.Room]bool)
+	for _, name := range names {
+		room := af.Rooms[name]
+		if reached[room] || grouped[room] {
+			continue
+		}
+		group := af.component(room, grouped)
+		sort.Slice(group, func(i, j int) bool {
+			return af.source.rooms[group[i]] < af.source.rooms[group[j]]
+		})
+
+		if hasRoom(group, af.End) {
+			report.findings = append(report.findings, Finding{
+				Line:    af.source.rooms[af.End],
+				Kind:    NoRoute,
+				Message: fmt.Sprintf("end room %s is not connected to start room %s", af.End.Name, af.Start.Name),
+			})
+			continue
+		}
+		report.disconnected = append(report.disconnected, group...)
+		report.findings = append(report.findings, Finding{
+			Line:    af.source.rooms[group[0]],
+			Kind:    Disconnected,
+			Message: fmt.Sprintf("%s not connected to start room %s", roomList(group), af.Start.Name),
+		})
+	}
+
+	if reached[af.End] {
+		useful := af.usefulLinks()
+		usefulRooms := make(map[*models.Room]bool)
+		for l := range useful {
+			usefulRooms[l.a], usefulRooms[l.b] = true, true
+		}
+		for _, name := range names {
+			room := af.Rooms[name]
+			if reached[room] && !usefulRooms[room] && room != af.Start && room != af.End {
+				report.deadEnds = append(report.deadEnds, room)
+				report.findings = append(report.findings, Finding{
+					Line:    af.source.rooms[room],
+					Kind:    DeadEnd,
+					Message: fmt.Sprintf("room %s is on no path from start to end", room.Name),
+				})
+			}
+		}
+		for _, name := range names {
+			room := af.Rooms[name]
+			for _, next := range room.Connected {
+				l := newLink(room, next)
+				if room.Name < next.Name && reached[room] && !useful[l] {
+					report.useless = append(report.useless, l)
+					report.findings = append(report.findings, Finding{
+						Line:    af.source.links[l],
+						Kind:    UselessLink,
+						Message: fmt.Sprintf("tunnel %s-%s is on no path from start to end", l.a.Name, l.b.Name),
+					})
+				}
+			}
+		}
+	}
+
+	sort.SliceStable(report.findings, func(i, j int) bool {
+		return report.findings[i].Line < report.findings[j].Line
+	})
+	return report
+}
+
+// usefulLinks runs Tarjan's biconnected components algorithm from the start,
+// with an extra tunnel from start to end, and returns the tunnels in the
+// extra tunnel's component
+func (af *AntFarm) usefulLinks() map[link]bool {
+	// Tunnels are numbered so the extra one can sit beside a real start-end tunnel
+	type tunnel struct{ a, b *models.Room }
+	tunnels := make([]tunnel, 0)
+	incident := make(map[*models.Room][]int)
+	addTunnel := func(a, b *models.Room) {
+		incident[a] = append(incident[a], len(tunnels))
+		incident[b] = append(incident[b], len(tunnels))
+		tunnels = append(tunnels, tunnel{a, b})
+	}
+	names := make([]string, 0, len(af.Rooms))
+	for name := range af.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		room := af.Rooms[name]
+		for _, next := range room.Connected {
+			if room.Name < next.Name {
+				addTunnel(room, next)
+			}
+		}
+	}
+	extra := len(tunnels)
+	addTunnel(af.Start, af.End)
+
+	type frame struct {
+		room *models.Room
+		via  int // tunnel the search arrived through, -1 at the start
+		next int // position in incident[room] to look at next
+	}
+	disc := map[*models.Room]int{af.Start: 1}
+	low := map[*models.Room]int{af.Start: 1}
+	stack := []frame{{af.Start, -1, 0}}
+	pending := make([]int, 0) // tunnels not yet assigned a component
+	useful := make(map[link]bool)
+
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		if top.next < len(incident[top.room]) {
+			id := incident[top.room][top.next]
+			top.next++
+			if id == top.via {
+				continue
+			}
+			other := tunnels[id].a
+			if other == top.room {
+				other = tunnels[id].b
+			}
+			if d, seen := disc[other]; seen {
+				if d < disc[top.room] {
+					pending = append(pending, id)
+					low[top.room] = min(low[top.room], d)
+				}
+				continue
+			}
+			pending = append(pending, id)
+			disc[other] = len(disc) + 1
+			low[other] = disc[other]
+			stack = append(stack, frame{other, id, 0})
+			continue
+		}
+
+		done := *top
+		stack = stack[:len(stack)-1]
+		if len(stack) == 0 {
+			break
+		}
+		parent := stack[len(stack)-1].room
+		low[parent] = min(low[parent], low[done.room])
+		if low[done.room] < disc[parent] {
+			continue
+		}
+
+		// parent separates done's subtree: its tunnels form one component
+		i := len(pending) - 1
+		for pending[i] != done.via {
+			i--
+		}
+		component := pending[i:]
+		pending = pending[:i]
+		for _, id := range component {
+			if id == extra {
+				for _, id := range component {
+					if id != extra {
+						useful[newLink(tunnels[id].a, tunnels[id].b)] = true
+					}
+				}
+				break
+			}
+		}
+	}
+	return useful
+}
+
+// component returns the rooms connected to room, marking them in seen
+func (af *AntFarm) component(room *models.Room, seen map[*models.Room]bool) []*models.Room {
+	seen[room] = true
+	group := []*models.Room{room}
+	for i := 0; i < len(group); i++ {
+		for _, next := range group[i].Connected {
+			if !seen[next] {
+				seen[next] = true
+				group = append(group, next)
+			}
+		}
+	}
+	return group
+}
+
+// roomList names rooms for a finding, e.g. "room a is" or "rooms a, b are"
+func roomList(rooms []*models.Room) string {
+	names := make([]string, len(rooms))
+	for i, room := range rooms {
+		names[i] = room.Name
+	}
+	if len(names) == 1 {
+		return "room " + names[0] + " is"
+	}
+	return "rooms " + strings.Join(names, ", ") + " are"
+}
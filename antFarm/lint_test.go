@@ -0,0 +1,121 @@
+package antfarm
+
+import (
+	"reflect"
+	"testing"
+)
+
+// deadEndFarm has a loop hanging off a, a pair of rooms nothing reaches and
+// a tunnel between two rooms that both lead only to the start
+const deadEndFarm = `3
+##start
+s 0 0
+a 1 0
+b 2 0
+d 1 2
+e 2 2
+x 5 5
+y 6 6
+p 0 1
+q 0 2
+##end
+t 3 0
+s-a
+a-b
+b-t
+a-d
+d-e
+e-a
+x-y
+s-p
+s-q
+p-q
+`
+
+func TestAntFarm_Lint(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{
+			name:  "clean farm",
+			input: "2\n##start\ns 0 0\na 1 0\n##end\nt 2 0\ns-a\na-t\ns-t\n",
+			want:  nil,
+		},
+		{
+			name:  "no route",
+			input: "2\n##start\ns 0 0\na 1 0\n##end\nt 2 0\ns-a\n",
+			want:  []string{"line 6: no route: end room t is not connected to start room s"},
+		},
+		{
+			name:  "dead ends and disconnected rooms",
+			input: deadEndFarm,
+			want: []string{
+				"line 6: dead end: room d is on no path from start to end",
+				"line 7: dead end: room e is on no path from start to end",
+				"line 8: disconnected: rooms x, y are not connected to start room s",
+				"line 10: dead end: room p is on no path from start to end",
+				"line 11: dead end: room q is on no path from start to end",
+				"line 17: useless link: tunnel a-d is on no path from start to end",
+				"line 18: useless link: tunnel d-e is on no path from start to end",
+				"line 19: useless link: tunnel a-e is on no path from start to end",
+				"line 21: useless link: tunnel p-s is on no path from start to end",
+				"line 22: useless link: tunnel q-s is on no path from start to end",
+				"line 23: useless link: tunnel p-q is on no path from start to end",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, tc.input)
+			var got []string
+			for _, finding := range af.Lint() {
+				got = append(got, finding.String())
+			}
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("Lint() = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAntFarm_Prune(t *testing.T) {
+	af := parseFarm(t, deadEndFarm)
+	before, err := af.Turns()
+	if err != nil {
+		t.Fatalf("Turns() error = %v", err)
+	}
+
+	rooms, links := af.Prune()
+	if rooms != 6 || links != 7 {
+		t.Errorf("Prune() = %d rooms, %d links, want 6 rooms, 7 links", rooms, links)
+	}
+	if len(af.Rooms) != 4 {
+		t.Errorf("Prune() left %d rooms, want 4", len(af.Rooms))
+	}
+	if findings := af.Lint(); len(findings) != 0 {
+		t.Errorf("Lint() after Prune() = %v, want none", findings)
+	}
+	if af.planner != nil {
+		t.Error("Prune() created a planner")
+	}
+
+	after, err := af.Turns()
+	if err != nil {
+		t.Fatalf("Turns() error = %v", err)
+	}
+	if after != before {
+		t.Errorf("Turns() after Prune() = %d, want %d", after, before)
+	}
+}
+
+func TestFindingKind_String(t *testing.T) {
+	if got := DeadEnd.String(); got != "dead end" {
+		t.Errorf("DeadEnd.String() = %q, want %q", got, "dead end")
+	}
+	if got := FindingKind(42).String(); got != "FindingKind(42)" {
+		t.Errorf("FindingKind(42).String() = %q, want %q", got, "FindingKind(42)")
+	}
+}
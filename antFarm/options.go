@@ -0,0 +1,117 @@
+package antfarm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Option adjusts a single simulation run without changing the farm
+type Option func(*runConfig) error
+
+// runConfig holds the settings of one run, starting from the farm's own
+type runConfig struct {
+	ctx       context.Context
+	bestSoFar bool
+	numAnts   int
+	maxAnts   int
+	finder    PathFinder // nil keeps the farm's own planning
+	assigner  Assigner
+	simplify  bool
+	routing   Routing
+}
+
+// WithAnts runs the simulation with numAnts ants instead of the number read
+// from the input
+func WithAnts(numAnts int) Option {
+	return func(cfg *runConfig) error {
+		if err := checkNumAnts(numAnts, cfg.maxAnts); err != nil {
+			return fmt.Errorf("number of ants: %w", err)
+		}
+		cfg.numAnts = numAnts
+		return nil
+	}
+}
+
+// WithPathFinder chooses the paths with finder instead of the default search
+func WithPathFinder(finder PathFinder) Option {
+	return func(cfg *runConfig) error {
+		if finder == nil {
+			return errors.New("nil path finder")
+		}
+		cfg.finder = finder
+		return nil
+	}
+}
+
+// WithAssigner spreads the ants over the paths with assigner instead of the
+// default Greedy
+func WithAssigner(assigner Assigner) Option {
+	return func(cfg *runConfig) error {
+		if assigner == nil {
+			return errors.New("nil assigner")
+		}
+		cfg.assigner = assigner
+		return nil
+	}
+}
+
+// WithSimplify searches a reduced copy of the farm: rooms and tunnels on no
+// path from start to end are left out and chains of rooms with two tunnels
+// each count as one weighted room. The paths found are mapped back onto the
+// farm's rooms, so the ants need as many turns as without it. The moves can
+// still differ where paths of equal worth tie, since the reduced farm is
+// numbered differently. The search of an edited farm starts afresh instead
+// of reusing the planner.
+func WithSimplify() Option {
+	return func(cfg *runConfig) error {
+		cfg.simplify = true
+		return nil
+	}
+}
+
+// WithRouting plays the run by the given routing rule instead of
+// VertexDisjoint: the paths are chosen so they never share what the rule
+// forbids, and the ants are moved and checked by it
+func WithRouting(routing Routing) Option {
+	return func(cfg *runConfig) error {
+		if routing < VertexDisjoint || int(routing) >= len(RoutingNames) {
+			return fmt.Errorf("unknown routing %d", int(routing))
+		}
+		cfg.routing = routing
+		return nil
+	}
+}
+
+// WithContext stops the run with the context's error once ctx ends, whether
+// it is still searching for paths or already moving the ants
+func WithContext(ctx context.Context) Option {
+	return func(cfg *runConfig) error {
+		if ctx == nil {
+			return errors.New("nil context")
+		}
+		cfg.ctx = ctx
+		return nil
+	}
+}
+
+// WithBestSoFar settles for the best paths found by the time the run's
+// context ends instead of failing, as long as the search found any. The ants
+// are then moved to the end without further regard to the context.
+func WithBestSoFar() Option {
+	return func(cfg *runConfig) error {
+		cfg.bestSoFar = true
+		return nil
+	}
+}
+
+// newRunConfig applies opts on top of the farm's settings
+func (af *AntFarm) newRunConfig(opts []Option) (runConfig, error) {
+	cfg := runConfig{ctx: context.Background(), numAnts: af.NumAnts, maxAnts: af.maxAnts(), assigner: Greedy}
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return runConfig{}, err
+		}
+	}
+	return cfg, nil
+}
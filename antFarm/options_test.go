@@ -0,0 +1,195 @@
+package antfarm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"test/generator"
+	"test/models"
+)
+
+func TestWithAnts(t *testing.T) {
+	testCases := []struct {
+		name      string
+		numAnts   int
+		wantTurns int
+		wantErr   error
+	}{
+		{"fewer ants than the file", 1, 3, nil},
+		{"more ants than the file", 10, 7, nil},
+		{"zero ants", 0, 0, models.ErrNonPositiveAnts},
+		{"over the limit", 10001, 0, models.ErrTooManyAnts},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, crossFarm)
+			got, err := af.Turns(WithAnts(tc.numAnts))
+			if !errors.Is(err, tc.wantErr) {
+				t.Fatalf("Turns() error = %v, want %v", err, tc.wantErr)
+			}
+			if got != tc.wantTurns {
+				t.Errorf("Turns() = %d, want %d", got, tc.wantTurns)
+			}
+			if af.NumAnts != 3 {
+				t.Errorf("WithAnts() changed the farm's ant count to %d", af.NumAnts)
+			}
+		})
+	}
+}
+
+func TestAntFarm_Turns(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	moves, err := af.SimulateMovement(WithAnts(5))
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+	turns, err := af.Turns(WithAnts(5))
+	if err != nil {
+		t.Fatalf("Turns() error = %v", err)
+	}
+
+	lines := 0
+	for _, c := range moves {
+		if c == '\n' {
+			lines++
+		}
+	}
+	if turns != lines {
+		t.Errorf("Turns() = %d, but the simulation printed %d turns", turns, lines)
+	}
+}
+
+// countdownContext ends once its Err method has been asked n times, so a
+// search can be cut short at the same point on every run
+type countdownContext struct {
+	context.Context
+	n int
+}
+
+func (c *countdownContext) Err() error {
+	if c.n <= 0 {
+		return context.Canceled
+	}
+	c.n--
+	return nil
+}
+
+func TestWithContext(t *testing.T) {
+	af := parseFarm(t, generatedInput(t, benchFarms[1]))
+
+	// Each search is stopped part way, once it has found a path
+	testCases := []struct {
+		finder PathFinder
+		checks int
+	}{
+		{DFSGreedy, 1},
+		{BFSShortest, 1},
+		{MaxFlow, 1},
+		{KShortestDisjoint, 1},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.finder.Name(), func(t *testing.T) {
+			full, err := af.Turns(WithPathFinder(tc.finder))
+			if err != nil {
+				t.Fatalf("Turns() error = %v", err)
+			}
+
+			ctx := &countdownContext{Context: context.Background(), n: tc.checks}
+			if _, err := af.Turns(WithPathFinder(tc.finder), WithContext(ctx)); !errors.Is(err, context.Canceled) {
+				t.Errorf("Turns() error = %v, want %v", err, context.Canceled)
+			}
+
+			ctx = &countdownContext{Context: context.Background(), n: tc.checks}
+			moves, err := af.SimulateMovement(WithPathFinder(tc.finder), WithContext(ctx), WithBestSoFar())
+			if err != nil {
+				t.Fatalf("SimulateMovement(WithBestSoFar()) error = %v", err)
+			}
+			v, err := af.Verify(strings.NewReader(moves))
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if v.Turns < full {
+				t.Errorf("SimulateMovement(WithBestSoFar()) took %d turns, fewer than the full search's %d", v.Turns, full)
+			}
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := af.Turns(WithContext(ctx), WithBestSoFar()); !errors.Is(err, context.Canceled) {
+		t.Errorf("Turns() with nothing found error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func TestWithContext_deadline(t *testing.T) {
+	// The full search of this farm takes far longer than the deadline
+	input := generatedInput(t, generator.Config{Preset: generator.Random, Seed: 1, Rooms: 60})
+	const deadline, slack = 300 * time.Millisecond, 250 * time.Millisecond
+
+	testCases := []struct {
+		name    string
+		opts    []Option
+		wantErr bool
+	}{
+		{"strict", nil, true},
+		{"best so far", []Option{WithBestSoFar()}, false},
+		{"simplified", []Option{WithSimplify()}, true},
+		{"edge routing", []Option{WithRouting(EdgeDisjoint)}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, input)
+			ctx, cancel := context.WithTimeout(context.Background(), deadline)
+			defer cancel()
+
+			start := time.Now()
+			_, err := af.Solve(append(tc.opts, WithContext(ctx))...)
+			if elapsed := time.Since(start); elapsed > deadline+slack {
+				t.Errorf("Solve() took %v, want at most %v past the %v deadline", elapsed, slack, deadline)
+			}
+			if gotErr := err != nil; gotErr != tc.wantErr || gotErr && !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("Solve() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+
+	t.Run("best so far paths", func(t *testing.T) {
+		// Each worker is still searching routes from its own first room at
+		// the deadline, so the paths found by then go one to a worker
+		af := parseFarm(t, generatedInput(t, generator.Config{Preset: generator.FlowThousand, Seed: 3, Rooms: 400, Density: 0.3}))
+		af.Workers = 4
+		ctx, cancel := context.WithTimeout(context.Background(), deadline)
+		defer cancel()
+
+		sol, err := af.Solve(WithContext(ctx), WithBestSoFar())
+		if err != nil {
+			t.Fatalf("Solve() error = %v", err)
+		}
+		if len(sol.Paths) != af.Workers {
+			t.Errorf("Solve() kept %d paths, want one for each of the %d workers", len(sol.Paths), af.Workers)
+		}
+	})
+
+	t.Run("anytime", func(t *testing.T) {
+		af := parseFarm(t, input)
+		ctx, cancel := context.WithTimeout(context.Background(), deadline)
+		defer cancel()
+
+		start := time.Now()
+		solutions, err := af.SolveAnytime(WithContext(ctx))
+		if err != nil {
+			t.Fatalf("SolveAnytime() error = %v", err)
+		}
+		for range solutions {
+		}
+		if elapsed := time.Since(start); elapsed > deadline+slack {
+			t.Errorf("SolveAnytime() took %v, want at most %v past the %v deadline", elapsed, slack, deadline)
+		}
+	})
+}
@@ -2,7 +2,9 @@ package antfarm
 
 import (
 	"bufio"
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"strconv"
 	"strings"
@@ -16,19 +18,35 @@ type parserState struct {
 	expectStart  bool
 	expectEnd    bool
 	parsingLinks bool
+	line         int // number of the line last read
 }
 
 // ParseInput reads and parses the input file for the ant farm configuration.
 // It returns an error if the file cannot be read or if the input format is invalid.
 func (af *AntFarm) ParseInput(filename string) error {
+	return af.ParseInputContext(context.Background(), filename)
+}
+
+// ParseInputContext is ParseInput stopping with the context's error once ctx ends.
+func (af *AntFarm) ParseInputContext(ctx context.Context, filename string) error {
 	file, err := os.Open(filename)
 	if err != nil {
 		return fmt.Errorf("opening file: %w", err)
 	}
 	defer file.Close()
 
+	return af.ParseReaderContext(ctx, file)
+}
+
+// ParseReader parses an ant farm configuration in the input file format from r.
+func (af *AntFarm) ParseReader(r io.Reader) error {
+	return af.ParseReaderContext(context.Background(), r)
+}
+
+// ParseReaderContext is ParseReader stopping with the context's error once ctx ends.
+func (af *AntFarm) ParseReaderContext(ctx context.Context, r io.Reader) error {
 	state := &parserState{
-		scanner: bufio.NewScanner(file),
+		scanner: bufio.NewScanner(contextReader{ctx, r}),
 	}
 
 	if err := af.parseNumAnts(state); err != nil {
@@ -43,38 +61,72 @@ func (af *AntFarm) ParseInput(filename string) error {
 		return fmt.Errorf("validating ant farm: %w", err)
 	}
 
-	af.initializeAnts()
 	return nil
 }
 
+// contextReader fails reads with the context's error once ctx ends
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
 // parseNumAnts reads and validates the number of ants from the first line.
 func (af *AntFarm) parseNumAnts(state *parserState) error {
-	const maxAnts = 10000
-
 	if !state.scanner.Scan() {
-		return &models.ParseError{Message: "empty file"}
+		if err := state.scanner.Err(); err != nil {
+			return err
+		}
+		return models.ErrEmptyFile
 	}
+	state.line++
 
 	numAnts, err := strconv.Atoi(state.scanner.Text())
 	if err != nil {
-		return &models.ParseError{Message: "invalid number of ants"}
+		return models.ErrInvalidNumAnts
+	}
+
+	if err := checkNumAnts(numAnts, af.maxAnts()); err != nil {
+		return err
+	}
+
+	af.NumAnts = numAnts
+	return nil
+}
+
+// DefaultMaxAnts is the largest colony accepted unless AntFarm.MaxAnts says otherwise.
+const DefaultMaxAnts = 10000
+
+// maxAnts returns the farm's limit on the number of ants.
+func (af *AntFarm) maxAnts() int {
+	if af.MaxAnts > 0 {
+		return af.MaxAnts
 	}
+	return DefaultMaxAnts
+}
 
+// checkNumAnts ensures the number of ants is within bounds.
+func checkNumAnts(numAnts, maxAnts int) error {
 	if numAnts <= 0 {
-		return &models.ParseError{Message: "number of ants must be positive"}
+		return models.ErrNonPositiveAnts
 	}
 
 	if numAnts > maxAnts {
-		return &models.ParseError{Message: "number of ants exceeds maximum limit"}
+		return models.ErrTooManyAnts
 	}
-
-	af.NumAnts = numAnts
 	return nil
 }
 
 // parseRoomsAndLinks processes the room definitions and link configurations.
 func (af *AntFarm) parseRoomsAndLinks(state *parserState) error {
 	for state.scanner.Scan() {
+		state.line++
 		line := state.scanner.Text()
 		if line == "" {
 			continue
@@ -112,7 +164,12 @@ func (af *AntFarm) handleComment(line string, state *parserState) {
 func (af *AntFarm) parseLine(line string, state *parserState) error {
 	if strings.Contains(line, "-") {
 		state.parsingLinks = true
-		return af.parseLink(line)
+		if err := af.parseLink(line); err != nil {
+			return err
+		}
+		name1, name2, _ := strings.Cut(line, "-")
+		af.source.setLink(newLink(af.Rooms[name1], af.Rooms[name2]), state.line)
+		return nil
 	}
 
 	if !state.parsingLinks {
@@ -125,10 +182,10 @@ func (af *AntFarm) parseLine(line string, state *parserState) error {
 // validate ensures the ant farm configuration is complete and valid.
 func (af *AntFarm) validate() error {
 	if af.Start == nil {
-		return &models.ParseError{Message: "no start room found"}
+		return models.ErrNoStart
 	}
 	if af.End == nil {
-		return &models.ParseError{Message: "no end room found"}
+		return models.ErrNoEnd
 	}
 	return nil
 }
@@ -143,6 +200,7 @@ func (af *AntFarm) parseRoom(line string, state *parserState) error {
 	if err := af.addRoom(room); err != nil {
 		return err
 	}
+	af.source.setRoom(room, state.line)
 	return nil
 }
 
@@ -150,26 +208,34 @@ func (af *AntFarm) parseRoom(line string, state *parserState) error {
 func (af *AntFarm) parseRoomDefinition(line string, state *parserState) (*models.Room, error) {
 	parts := strings.Fields(line)
 	if len(parts) != 3 {
-		return nil, &models.ParseError{Message: "invalid room format"}
-	}
-
-	name := parts[0]
-	if _, exists := af.Rooms[name]; exists {
-		return nil, &models.ParseError{Message: "duplicate room name"}
+		return nil, models.ErrInvalidRoomFormat
 	}
 
 	x, err1 := strconv.Atoi(parts[1])
 	y, err2 := strconv.Atoi(parts[2])
 	if err1 != nil || err2 != nil {
-		return nil, &models.ParseError{Message: "invalid room coordinates"}
+		return nil, models.ErrInvalidCoordinates
+	}
+
+	return af.newRoom(parts[0], x, y, state.expectStart, state.expectEnd)
+}
+
+// newRoom checks a room name against the farm and creates the room.
+func (af *AntFarm) newRoom(name string, x, y int, isStart, isEnd bool) (*models.Room, error) {
+	if name == "" || strings.ContainsAny(name, "- \t") || strings.HasPrefix(name, "#") {
+		return nil, models.ErrInvalidRoomName
+	}
+
+	if _, exists := af.Rooms[name]; exists {
+		return nil, models.ErrDuplicateRoom
 	}
 
 	return &models.Room{
 		Name:      name,
 		X:         x,
 		Y:         y,
-		IsStart:   state.expectStart,
-		IsEnd:     state.expectEnd,
+		IsStart:   isStart,
+		IsEnd:     isEnd,
 		Connected: make([]*models.Room, 0),
 	}, nil
 }
@@ -178,14 +244,14 @@ func (af *AntFarm) parseRoomDefinition(line string, state *parserState) (*models
 func (af *AntFarm) addRoom(room *models.Room) error {
 	if room.IsStart {
 		if af.Start != nil {
-			return &models.ParseError{Message: "multiple start rooms defined"}
+			return models.ErrMultipleStart
 		}
 		af.Start = room
 	}
 
 	if room.IsEnd {
 		if af.End != nil {
-			return &models.ParseError{Message: "multiple end rooms defined"}
+			return models.ErrMultipleEnd
 		}
 		af.End = room
 	}
@@ -194,28 +260,32 @@ func (af *AntFarm) addRoom(room *models.Room) error {
 	return nil
 }
 
-
 // parseLink parses a link definition line
 func (af *AntFarm) parseLink(line string) error {
 	parts := strings.Split(line, "-")
-	if len(parts) != 2 || parts[0] == parts[1] {
-		return &models.ParseError{Message: "invalid link format"}
+	if len(parts) != 2 {
+		return models.ErrInvalidLink
 	}
-	room1, exists1 := af.Rooms[parts[0]]
-	room2, exists2 := af.Rooms[parts[1]]
+	return af.addLink(parts[0], parts[1])
+}
+
+// addLink connects two existing rooms in both directions
+func (af *AntFarm) addLink(name1, name2 string) error {
+	if name1 == name2 {
+		return models.ErrInvalidLink
+	}
+	room1, exists1 := af.Rooms[name1]
+	room2, exists2 := af.Rooms[name2]
 	if !exists1 || !exists2 {
-		return &models.ParseError{Message: "link references nonexistent room"}
+		return models.ErrUnknownRoom
 	}
 	// Check if link already exists
 	for _, connected := range room1.Connected {
 		if connected.Name == room2.Name {
-			return &models.ParseError{Message: "duplicate link"}
+			return models.ErrDuplicateLink
 		}
 	}
 	room1.Connected = append(room1.Connected, room2)
 	room2.Connected = append(room2.Connected, room1)
 	return nil
 }
-
-
-
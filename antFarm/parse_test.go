@@ -2,6 +2,10 @@ package antfarm
 
 import (
 	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
 	"reflect"
 	"strings"
 	"testing"
@@ -365,3 +369,73 @@ func contains(rooms []*models.Room, room *models.Room) bool {
 	}
 	return false
 }
+
+func TestParseNumAnts_maxAnts(t *testing.T) {
+	af := &AntFarm{MaxAnts: 50000}
+	state := &parserState{
+		scanner: bufio.NewScanner(strings.NewReader("20000")),
+	}
+	if err := af.parseNumAnts(state); err != nil {
+		t.Fatalf("ParseNumAnts() error = %v", err)
+	}
+	if af.NumAnts != 20000 {
+		t.Errorf("ParseNumAnts() got = %d, want 20000", af.NumAnts)
+	}
+
+	af = &AntFarm{MaxAnts: 5}
+	state = &parserState{
+		scanner: bufio.NewScanner(strings.NewReader("6")),
+	}
+	if err := af.parseNumAnts(state); err == nil {
+		t.Errorf("ParseNumAnts() accepted more ants than MaxAnts")
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"valid farm", "2\n##start\ns 0 0\n##end\ne 1 1\ns-e\n", false},
+		{"empty input", "", true},
+		{"missing end room", "2\n##start\ns 0 0\n", true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := NewAntFarm()
+			err := af.ParseReader(strings.NewReader(tc.input))
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ParseReader() error = %v, wantErr %v", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestAntFarm_ParseReaderContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := NewAntFarm().ParseReaderContext(ctx, strings.NewReader("2\n##start\ns 0 0\n##end\ne 1 1\ns-e\n"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("ParseReaderContext() error = %v, want %v", err, context.Canceled)
+	}
+}
+
+func BenchmarkParseInput(b *testing.B) {
+	for _, cfg := range benchFarms {
+		b.Run(string(cfg.Preset), func(b *testing.B) {
+			filename := filepath.Join(b.TempDir(), "farm.txt")
+			if err := os.WriteFile(filename, []byte(generatedInput(b, cfg)), 0o644); err != nil {
+				b.Fatal(err)
+			}
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := NewAntFarm().ParseInput(filename); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -1,136 +1,220 @@
 package antfarm
 
 import (
-	"sort"
+	"context"
 
 	"test/models"
 )
 
 // findAllPaths traverses the colony using a depth-first to return sorted non-overlapping paths
 func (af *AntFarm) findAllPaths() []models.Path {
-	paths := make([]models.Path, 0)
-	visited := make(map[*models.Room]bool)
-	currentPath := make([]*models.Room, 0)
-
-	af.dfs(af.Start, af.End, visited, currentPath, &paths)
-	// Sort paths by length
-	sort.Slice(paths, func(i, j int) bool {
-		return paths[i].Length < paths[j].Length
-	})
+	return af.searchPaths(context.Background(), VertexDisjoint)
+}
 
+// searchPaths is findAllPaths stopping once ctx ends, with the best
+// combination of the paths found by then that routing allows together
+func (af *AntFarm) searchPaths(ctx context.Context, routing Routing) []models.Path {
 	// Filter out overlapping paths
-	nonOverlappingPaths := af.filterNonOverlappingPaths(paths)
+	candidates := af.candidatePaths(ctx)
+	return keptPaths(candidates, selectNonOverlapping(ctx, candidates, routing))
+}
+
+// candidatePaths returns every simple path from start to end sorted by
+// length, keeping the search order between equal lengths. When ctx ends
+// only the paths found by then are returned.
+func (af *AntFarm) candidatePaths(ctx context.Context) []models.Path {
+	return sortByLength(af.dfsParallel(ctx, af.workers()))
+}
+
+// sortByLength orders paths by length, keeping their order between equal
+// lengths. Lengths are small, so the paths are bucketed by length rather than
+// compared, which keeps sorting millions of candidates quick.
+func sortByLength(paths []models.Path) []models.Path {
+	longest := 0
+	for _, path := range paths {
+		longest = max(longest, path.Length)
+	}
+	next := make([]int, longest+2) // next[l] is where the next path of length l goes
+	for _, path := range paths {
+		next[path.Length+1]++
+	}
+	for l := 1; l < len(next); l++ {
+		next[l] += next[l-1]
+	}
 
-	return nonOverlappingPaths
+	sorted := make([]models.Path, len(paths))
+	for _, path := range paths {
+		sorted[next[path.Length]] = path
+		next[path.Length]++
+	}
+	return sorted
 }
 
 // filterNonOverlappingPaths filters and returns the best combination of non-overlapping paths from a list of paths. 
 // The function avoids overlaps by ensuring that no two paths in the final result share any "middle" rooms (rooms 
 // that are not the start or end). 
 func (af *AntFarm) filterNonOverlappingPaths(paths []models.Path) []models.Path {
-	// Helper function to check if two paths overlap
-	pathsOverlap := func(path1, path2 models.Path) bool {
-		rooms1 := make(map[string]struct{})
-
-		// Add middle rooms from path1 to set1 (excluding start and end)
-		for i := 1; i < len(path1.Rooms)-1; i++ {
-			rooms1[path1.Rooms[i].Name] = struct{}{}
-		}
+	return keptPaths(paths, selectNonOverlapping(context.Background(), paths, VertexDisjoint))
+}
 
-		// Check if any middle room from path2 exists in set1
-		for i := 1; i < len(path2.Rooms)-1; i++ {
-			if _, exists := rooms1[path2.Rooms[i].Name]; exists {
-				return true
-			}
-		}
-		return false
+// keptPaths returns the paths a selection kept, in the order it added them
+func keptPaths(paths []models.Path, sel pathSelection) []models.Path {
+	result := make([]models.Path, len(sel.kept))
+	for i, k := range sel.kept {
+		result[i] = paths[k]
 	}
+	return result
+}
 
-	// Helper function to find best combination of non-overlapping paths
-	findBestCombination := func(paths []models.Path) []models.Path {
-		n := len(paths)
-		if n == 0 {
-			return []models.Path{}
-		}
+// overlap records why a candidate path was left out: it shares a room or
+// tunnel, named by shared, with the kept candidate at index path
+type overlap struct {
+	path   int
+	shared string
+}
 
-		bestCombination := []models.Path{paths[0]} // Initialize with first path
-		maxPaths := 1
+// pathSelection is how filterNonOverlappingPaths chose from its candidates:
+// the seed of the winning combination and the candidates kept in the order
+// they were added
+type pathSelection struct {
+	seed int
+	kept []int
+}
 
-		// Function to check if a path is compatible with a combination
-		isCompatible := func(path models.Path, combination []models.Path) bool {
-			for _, existingPath := range combination {
-				if pathsOverlap(path, existingPath) {
-					return false
-				}
-			}
-			return true
+// selectNonOverlapping tries each path as the seed of a combination and adds
+// every other path that does not overlap the ones already taken, in order.
+// Paths overlap when they share what routing forbids. The first combination
+// with the most paths wins. When ctx ends the best of the combinations tried
+// so far is returned, the one being built included, but the combination
+// seeded with the first path is always finished, so the paths of a search cut
+// short are still combined.
+func selectNonOverlapping(ctx context.Context, paths []models.Path, routing Routing) pathSelection {
+	best := pathSelection{}
+	taken := make(map[string]bool) // what the kept paths hold
+	ended := false
+	for seed := range paths {
+		clear(taken)
+		sel := pathSelection{seed: seed, kept: []int{seed}}
+		for _, name := range routing.holds(paths[seed]) {
+			taken[name] = true
 		}
 
-		// Try each path as the starting path
-		for startIdx := 0; startIdx < n; startIdx++ {
-			currentCombination := []models.Path{paths[startIdx]}
-
-			// Try to add other paths
-			for i := 0; i < n; i++ {
-				if i == startIdx {
-					continue
-				}
-
-				// Check if current path can be added
-				if isCompatible(paths[i], currentCombination) {
-					currentCombination = append(currentCombination, paths[i])
-				}
+		for i := range paths {
+			ended = ended || ctx.Err() != nil
+			if ended && seed > 0 {
+				break
 			}
-
-			// Update best combination if current is better
-			if len(currentCombination) > maxPaths {
-				maxPaths = len(currentCombination)
-				bestCombination = make([]models.Path, len(currentCombination))
-				copy(bestCombination, currentCombination)
+			if i == seed || routing.shares(paths[i], taken) {
+				continue
 			}
+			for _, name := range routing.holds(paths[i]) {
+				taken[name] = true
+			}
+			sel.kept = append(sel.kept, i)
 		}
 
-		return bestCombination
+		if len(sel.kept) > len(best.kept) {
+			best = sel
+		}
+		if ended {
+			break
+		}
 	}
-
-	result := findBestCombination(paths)
-	return result
+	return best
 }
 
-// assignAntsToPath assigns ants to optimal paths
-func (af *AntFarm) assignAntsToPath() map[*models.Ant]models.Path {
-	paths := af.findAllPaths()
-	if len(paths) == 0 {
-		return nil
+// rejected returns why each candidate the selection left out was rejected,
+// by index. A candidate is only checked against the paths kept before it and
+// the paths kept later come after those, so the earliest kept path it shares
+// with is the one that blocked it. Candidates a selection cut short never
+// reached are left out unless one of the kept paths blocks them.
+func (sel pathSelection) rejected(paths []models.Path, routing Routing) map[int]overlap {
+	owner := make(map[string]int) // what the kept paths hold, to the position of its path in kept
+	kept := make(map[int]bool, len(sel.kept))
+	for pos, k := range sel.kept {
+		kept[k] = true
+		for _, name := range routing.holds(paths[k]) {
+			owner[name] = pos
+		}
 	}
 
-	antPaths := make(map[*models.Ant]models.Path)
-	pathAnts := make([]int, len(paths)) // Tracks the number of ants assigned to each path
-	totalMoves := 0                     // Tracks the total moves required for all ants
+	rejected := make(map[int]overlap)
+	for i, path := range paths {
+		if kept[i] {
+			continue
+		}
+		if o, blocked := sel.blockedBy(owner, routing.holds(path)); blocked {
+			rejected[i] = o
+		}
+	}
+	return rejected
+}
 
-	// Assign ants to paths by minimizing total moves
-	for i := 0; i < len(af.Ants); i++ {
-		bestTurns := int(^uint(0) >> 1) // Max int to find the path with the least moves
-		bestPathIndex := 0
+// blockedBy reports the first kept path, in the order they were added, that
+// holds any of names, and the first of names it holds
+func (sel pathSelection) blockedBy(owner map[string]int, names []string) (overlap, bool) {
+	first, shared := -1, ""
+	for _, name := range names {
+		if pos, taken := owner[name]; taken && (first < 0 || pos < first) {
+			first, shared = pos, name
+		}
+	}
+	if first < 0 {
+		return overlap{}, false
+	}
+	return overlap{path: sel.kept[first], shared: shared}, true
+}
 
-		// Find the best path for the current ant (with the fewest total moves)
-		for j, path := range paths {
-			antsOnPath := pathAnts[j]
-			totalTurns := path.Length + antsOnPath
+// antCounts works out how many of numAnts ants the Greedy assigner puts on
+// each path, without placing ants one at a time. Every ant takes the path
+// where it arrives first, ties going to the lower index, so the paths fill up
+// to a common arrival turn and the ants left over go to the lowest-indexed
+// paths that can still take one more.
+func antCounts(lengths []int, numAnts int) []int {
+	counts := make([]int, len(lengths))
+	if len(lengths) == 0 || numAnts <= 0 {
+		return counts
+	}
 
-			// Choose the path with the fewest moves
-			if totalTurns < bestTurns {
-				bestTurns = totalTurns
-				bestPathIndex = j
+	// arrivals counts the ants that can have arrived by the given turn
+	arrivals := func(turn int) int {
+		n := 0
+		for _, length := range lengths {
+			if turn >= length {
+				n += turn - length + 1
 			}
 		}
+		return n
+	}
 
-		// Assign the ant to the best path found
-		antPaths[af.Ants[i]] = paths[bestPathIndex]
-		pathAnts[bestPathIndex]++
-		totalMoves += bestTurns // Add the moves for this assignment to the total moves
+	shortest := lengths[0]
+	for _, length := range lengths {
+		shortest = min(shortest, length)
 	}
 
-	return antPaths
-}
+	// Find the turn the last ant arrives on
+	low, high := shortest, shortest+numAnts-1
+	for low < high {
+		mid := low + (high-low)/2
+		if arrivals(mid) >= numAnts {
+			high = mid
+		} else {
+			low = mid + 1
+		}
+	}
 
+	left := numAnts
+	for j, length := range lengths {
+		if low > length {
+			counts[j] = low - length
+			left -= counts[j]
+		}
+	}
+	for j, length := range lengths {
+		if left > 0 && length <= low {
+			counts[j]++
+			left--
+		}
+	}
+	return counts
+}
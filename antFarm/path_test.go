@@ -1,7 +1,9 @@
 package antfarm
 
 import (
+	"context"
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -476,22 +478,21 @@ func TestAntFarm_filterNonOverlappingPaths(t *testing.T) {
 	}
 }
 
-// Helper function to create a string representation of the ant-path map
-func formatAntPathMap(m map[*models.Ant]models.Path) string {
+// Helper function to create a string representation of a dispatch
+func formatDispatch(d models.Dispatch) string {
 	var result strings.Builder
-	result.WriteString("map[\n")
+	result.WriteString("[\n")
 
-	// Convert map to sorted slice for consistent output
 	var antPaths []string
-	for ant, path := range m {
+	for i, j := range d.PathOf {
 		// Collect room names from the path
 		var roomNames []string
-		for _, room := range path.Rooms {
+		for _, room := range d.Paths[j].Rooms {
 			roomNames = append(roomNames, room.Name)
 		}
 
-		antPath := fmt.Sprintf("    Ant(ID: %d): Path[%s]",
-			ant.Id,
+		antPath := fmt.Sprintf("    Ant(ID: %d): Path %d[%s]",
+			i+1, j,
 			strings.Join(roomNames, " -> "))
 		antPaths = append(antPaths, antPath)
 	}
@@ -501,7 +502,57 @@ func formatAntPathMap(m map[*models.Ant]models.Path) string {
 	return result.String()
 }
 
-func TestAntFarm_assignAntsToPath(t *testing.T) {
+func TestSelectNonOverlapping(t *testing.T) {
+	// The first two candidates, s-a-t and s-b-t, go together
+	af := parseFarm(t, `2
+##start
+s 0 0
+a 1 0
+b 1 1
+##end
+t 2 0
+s-a
+s-b
+a-b
+a-t
+b-t
+`)
+	paths := af.candidatePaths(context.Background())
+	ended, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	testCases := []struct {
+		name string
+		ctx  context.Context
+		want pathSelection
+	}{
+		{"full", context.Background(), pathSelection{seed: 0, kept: []int{0, 1}}},
+		// The combination of the first seed is finished whatever happens
+		{"ended", ended, pathSelection{seed: 0, kept: []int{0, 1}}},
+		{"ended after the first seed", &countdownContext{Context: context.Background(), n: len(paths) + 1}, pathSelection{seed: 0, kept: []int{0, 1}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := selectNonOverlapping(tc.ctx, paths, VertexDisjoint); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("selectNonOverlapping() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestPathSelection_rejected(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	paths := af.candidatePaths(context.Background())
+	sel := selectNonOverlapping(context.Background(), paths, VertexDisjoint)
+
+	want := map[int]overlap{0: {path: 1, shared: "a"}, 3: {path: 1, shared: "a"}}
+	if got := sel.rejected(paths, VertexDisjoint); !reflect.DeepEqual(got, want) {
+		t.Errorf("rejected() = %+v, want %+v", got, want)
+	}
+}
+
+func TestAntFarm_Solve_dispatch(t *testing.T) {
 	// Helper function to create ants
 	createAnts := func(count int) []*models.Ant {
 		ants := make([]*models.Ant, count)
@@ -544,7 +595,7 @@ func TestAntFarm_assignAntsToPath(t *testing.T) {
 	tests := []struct {
 		name   string
 		fields fields
-		want   map[*models.Ant]models.Path
+		want   []int
 	}{
 		{
 			name: "Empty farm - no ants",
@@ -575,16 +626,7 @@ func TestAntFarm_assignAntsToPath(t *testing.T) {
 				Start: createRoom("start", 0, 0, true, false),
 				End:   createRoom("end", 1, 1, false, true),
 			},
-			want: func() map[*models.Ant]models.Path {
-				start := createRoom("start", 0, 0, true, false)
-				end := createRoom("end", 1, 1, false, true)
-				return map[*models.Ant]models.Path{
-					{Id: 1}: {
-						Rooms:  []*models.Room{start, end},
-						Length: 1,
-					},
-				}
-			}(),
+			want: []int{0},
 		},
 		{
 			name: "Multiple paths - multiple ants",
@@ -612,30 +654,8 @@ func TestAntFarm_assignAntsToPath(t *testing.T) {
 				Start: createRoom("start", 0, 0, true, false),
 				End:   createRoom("end", 2, 0, false, true),
 			},
-			want: func() map[*models.Ant]models.Path {
-				ants := createAnts(3)
-				path1 := models.Path{
-					Rooms: []*models.Room{
-						createRoom("start", 0, 0, true, false),
-						createRoom("middle1", 1, 0, false, false),
-						createRoom("end", 2, 0, false, true),
-					},
-					Length: 2,
-				}
-				path2 := models.Path{
-					Rooms: []*models.Room{
-						createRoom("start", 0, 0, true, false),
-						createRoom("middle1", 1, 0, false, false),
-						createRoom("end", 2, 0, false, true),
-					},
-					Length: 2,
-				}
-				return map[*models.Ant]models.Path{
-					ants[0]: path1,
-					ants[1]: path2,
-					ants[2]: path1,
-				}
-			}(),
+			// Both paths have length 2; the tie goes to the first path
+			want: []int{0, 1, 0},
 		},
 		{
 			name: "No valid paths",
@@ -668,23 +688,91 @@ func TestAntFarm_assignAntsToPath(t *testing.T) {
 				End:     tt.fields.Rooms["end"],
 			}
 
-			// Call findAllPaths() to ensure paths are discovered
-			// paths := af.findAllPaths()
-			// if len(paths) == 0 {
-			// 	t.Errorf("findAllPaths() returned 0 paths, expected at least 1 for test case %s", tt.name)
-			// } else {
-			// 	for _, path := range paths {
-			// 		t.Logf("Discovered path: %v", path)
-			// 	}
-			// }
-
-			// Call the assignAntsToPath() method
-			got := af.assignAntsToPath()
-			if len(got) != len(tt.want) {
-				t.Errorf("\nAntFarm.assignAntsToPath()\nTest: %s\ngot = %v\nwant = %v",
+			// Farms without a route or ants have nothing to dispatch
+			var got models.Dispatch
+			if sol, err := af.Solve(); err == nil {
+				got = sol.Dispatch()
+			}
+			if !reflect.DeepEqual(got.PathOf, tt.want) {
+				t.Errorf("\nAntFarm.Solve().Dispatch()\nTest: %s\ngot = %v\nwant = %v",
 					tt.name,
-					formatAntPathMap(got),
-					formatAntPathMap(tt.want))
+					formatDispatch(got),
+					tt.want)
+			}
+		})
+	}
+}
+
+func TestGreedy_dispatch(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lengths []int
+		numAnts int
+		want    []int
+	}{
+		{"no ants", []int{2}, 0, []int{}},
+		{"shortest first, then tie", []int{3, 2}, 2, []int{1, 0}},
+		{"ties to lowest index", []int{3, 2, 3}, 5, []int{1, 0, 1, 2, 0}},
+		{"equal paths alternate", []int{4, 4}, 4, []int{0, 1, 0, 1}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			paths := lengthPaths(tc.lengths...)
+			for run := 0; run < 10; run++ {
+				if got := Greedy.Assign(paths, tc.numAnts).Dispatch().PathOf; !reflect.DeepEqual(got, tc.want) {
+					t.Fatalf("Dispatch() run %d = %v, want %v", run, got, tc.want)
+				}
+			}
+		})
+	}
+}
+
+func TestAntCounts(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lengths []int
+		numAnts int
+	}{
+		{"no ants", []int{2, 3}, 0},
+		{"single path", []int{4}, 7},
+		{"equal paths", []int{3, 3, 3}, 10},
+		{"mixed lengths", []int{1, 3, 6}, 20},
+		{"unsorted lengths", []int{5, 2, 2, 9}, 33},
+		{"long path unused", []int{1, 40}, 12},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Send the ants one at a time down the path where each arrives
+			// first, ties going to the lowest index
+			want := make([]int, len(tc.lengths))
+			for ant := 0; ant < tc.numAnts; ant++ {
+				best := 0
+				for j, length := range tc.lengths {
+					if length+want[j] < tc.lengths[best]+want[best] {
+						best = j
+					}
+				}
+				want[best]++
+			}
+
+			got := antCounts(tc.lengths, tc.numAnts)
+			if fmt.Sprint(got) != fmt.Sprint(want) {
+				t.Errorf("antCounts() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func BenchmarkAntFarm_findAllPaths(b *testing.B) {
+	for _, cfg := range benchFarms {
+		b.Run(string(cfg.Preset), func(b *testing.B) {
+			af := parseFarm(b, generatedInput(b, cfg))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				af.findAllPaths()
 			}
 		})
 	}
@@ -0,0 +1,411 @@
+package antfarm
+
+import (
+	"context"
+	"sort"
+
+	"test/models"
+)
+
+// arc is one direction of a tunnel
+type arc struct {
+	from, to *models.Room
+}
+
+// side is the entry or exit half of a room once it is split for the flow
+type side struct {
+	room *models.Room
+	exit bool
+}
+
+// planner keeps a maximum set of vertex-disjoint paths from start to end of
+// least total length as a unit flow over the farm. Edits only re-augment the
+// flow they touch and cancel any shortcuts they open, so the previous plan is
+// the warm start for the next one.
+type planner struct {
+	flow  map[arc]bool
+	paths []models.Path // decomposition of flow, sorted by length
+}
+
+// newPlanner builds a planner for the farm starting from an empty flow. When
+// ctx ends it stops augmenting and keeps the paths found by then.
+func newPlanner(ctx context.Context, af *AntFarm) *planner {
+	p := &planner{flow: make(map[arc]bool)}
+	for ctx.Err() == nil && p.augment(af) {
+	}
+	p.paths = p.decompose(af)
+	return p
+}
+
+// successor returns the room the flow leaves room for, or nil
+func (p *planner) successor(room *models.Room) *models.Room {
+	for _, next := range room.Connected {
+		if p.flow[arc{room, next}] {
+			return next
+		}
+	}
+	return nil
+}
+
+// predecessor returns the room the flow enters room from, or nil
+func (p *planner) predecessor(room *models.Room) *models.Room {
+	for _, prev := range room.Connected {
+		if p.flow[arc{prev, room}] {
+			return prev
+		}
+	}
+	return nil
+}
+
+// saturate augments the flow until no more paths fit and reports whether it grew
+func (p *planner) saturate(af *AntFarm) bool {
+	grew := false
+	for p.augment(af) {
+		grew = true
+	}
+	return grew
+}
+
+// replan brings the plan up to date after an edit removed one of its paths:
+// the flow is shortened where the freed rooms allow, then grown with any
+// paths that now fit. The paths are only decomposed again when the flow
+// changed, here or by the edit.
+func (p *planner) replan(af *AntFarm, changed bool) {
+	if p.cancelCycles(af) {
+		changed = true
+	}
+	if p.saturate(af) || changed {
+		p.paths = p.decompose(af)
+	}
+}
+
+// residual calls visit with every side the residual network leads to from s
+// and what the step costs. Every middle room is split into an entry and an
+// exit side joined by a unit arc, which keeps the paths vertex-disjoint.
+// Tunnels cost one move and crossing a room of a simplified farm the rooms it
+// stands for; walking the flow backwards gives the cost back.
+func (p *planner) residual(af *AntFarm, s side, visit func(to side, cost int)) {
+	room := s.room
+	if !s.exit {
+		switch pred := p.predecessor(room); {
+		case room == af.End:
+			for _, prev := range room.Connected {
+				if p.flow[arc{prev, room}] {
+					visit(side{prev, true}, -1)
+				}
+			}
+		case pred != nil:
+			visit(side{pred, true}, -1)
+		default:
+			visit(side{room, true}, af.weights[room])
+		}
+		return
+	}
+
+	if room == af.End {
+		return
+	}
+	if room != af.Start && p.successor(room) != nil {
+		visit(side{room, false}, -af.weights[room])
+	}
+	for _, next := range room.Connected {
+		if next != af.Start && !p.flow[arc{room, next}] {
+			visit(side{next, false}, 1)
+		}
+	}
+}
+
+// push sends one unit along the residual step from one side to the next
+func (p *planner) push(from, to side) {
+	switch {
+	case from.exit && !to.exit && from.room != to.room:
+		p.flow[arc{from.room, to.room}] = true
+		if p.flow[arc{to.room, from.room}] {
+			delete(p.flow, arc{from.room, to.room})
+			delete(p.flow, arc{to.room, from.room})
+		}
+	case !from.exit && to.exit && from.room != to.room:
+		delete(p.flow, arc{to.room, from.room})
+	}
+}
+
+// augment finds the cheapest augmenting path in the residual network and
+// applies it. As long as the flow is as short as any of its size, so is the
+// flow after augmenting.
+func (p *planner) augment(af *AntFarm) bool {
+	if af.Start == nil || af.End == nil {
+		return false
+	}
+
+	first, sink := side{af.Start, true}, side{af.End, false}
+	dist, prev := p.distances(af, first, func(from, _ side) bool { return from == sink })
+	if _, reached := dist[sink]; !reached {
+		return false
+	}
+
+	// Apply the path, walking back from the end
+	for at := sink; at != first; at = prev[at] {
+		p.push(prev[at], at)
+	}
+	return true
+}
+
+// distances finds the cheapest way from first to every side the residual
+// network reaches without the steps skip leaves out, and the side before
+// each on that way. Walking flow backwards refunds its cost, so distances
+// come from Bellman-Ford rather than a plain search; that ends because the
+// flow is as short as any of its size, which leaves no cycle of negative
+// cost to go round.
+func (p *planner) distances(af *AntFarm, first side, skip func(from, to side) bool) (map[side]int, map[side]side) {
+	dist := map[side]int{first: 0}
+	prev := make(map[side]side)
+	inQueue := map[side]bool{first: true}
+
+	// Sides closer than the next in line jump the queue, which spares most
+	// of the passes a refund would otherwise set off: front is taken from
+	// its end before queue from its start
+	var front []side
+	queue := []side{first}
+	for len(front) > 0 || len(queue) > 0 {
+		var cur side
+		if len(front) > 0 {
+			cur, front = front[len(front)-1], front[:len(front)-1]
+		} else {
+			cur, queue = queue[0], queue[1:]
+		}
+		inQueue[cur] = false
+
+		p.residual(af, cur, func(to side, cost int) {
+			if skip(cur, to) {
+				return
+			}
+			if d, seen := dist[to]; seen && d <= dist[cur]+cost {
+				return
+			}
+			dist[to] = dist[cur] + cost
+			prev[to] = cur
+			if inQueue[to] {
+				return
+			}
+			inQueue[to] = true
+			switch {
+			case len(front) > 0 && dist[to] < dist[front[len(front)-1]],
+				len(front) == 0 && len(queue) > 0 && dist[to] < dist[queue[0]]:
+				front = append(front, to)
+			default:
+				queue = append(queue, to)
+			}
+		})
+	}
+	return dist, prev
+}
+
+// linked brings the plan up to date once a tunnel joins a and b. The flow
+// was as short as any of its size before, so a cycle of negative cost the
+// tunnel opens has to run through it, and cancelling the cheapest such cycle
+// makes the flow as short as any again. Only the way back to the tunnel is
+// searched rather than every side of the farm. The flow is then grown with
+// any paths that now fit.
+func (p *planner) linked(af *AntFarm, a, b *models.Room) {
+	if af.Start == nil || af.End == nil {
+		return
+	}
+	opened := func(from, to side) bool {
+		return from.exit && !to.exit && (from.room == a && to.room == b || from.room == b && to.room == a)
+	}
+
+	var cycle []side
+	cheapest := 0
+	// Nothing flows through a room the tunnel is the only way into, so no
+	// cycle can come back through it
+	steps := [][2]side{{{a, true}, {b, false}}, {{b, true}, {a, false}}}
+	if len(a.Connected) == 1 || len(b.Connected) == 1 {
+		steps = nil
+	}
+	for _, step := range steps {
+		from, to := step[0], step[1]
+		cost, open := 0, false
+		p.residual(af, from, func(next side, c int) {
+			if next == to {
+				cost, open = c, true
+			}
+		})
+		if !open {
+			continue
+		}
+
+		// The way back from the far side of the tunnel, without crossing it
+		dist, prev := p.distances(af, to, opened)
+		back, reached := dist[from]
+		if !reached || back+cost >= cheapest {
+			continue
+		}
+		cheapest = back + cost
+		cycle = []side{from}
+		for at := from; at != to; {
+			at = prev[at]
+			cycle = append(cycle, at)
+		}
+		// Reversed, the cycle runs from the far side of the tunnel back round
+		// to it, and the tunnel closes it
+		for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+			cycle[i], cycle[j] = cycle[j], cycle[i]
+		}
+	}
+
+	changed := cycle != nil
+	for i, to := range cycle {
+		p.push(cycle[(i+len(cycle)-1)%len(cycle)], to)
+	}
+	if p.saturate(af) || changed {
+		p.paths = p.decompose(af)
+	}
+}
+
+// cancelCycles sends flow around cycles of negative cost in the residual
+// network until none are left, and reports whether the flow changed. Edits
+// can open such cycles, e.g. with a shortcut beside a planned path; with them
+// gone the paths are again as short as any set of as many paths.
+func (p *planner) cancelCycles(af *AntFarm) bool {
+	changed := false
+	for {
+		cycle := p.negativeCycle(af)
+		if cycle == nil {
+			return changed
+		}
+		for i, to := range cycle {
+			p.push(cycle[(i+len(cycle)-1)%len(cycle)], to)
+		}
+		changed = true
+	}
+}
+
+// negativeCycle returns the sides of a cycle of negative cost in the residual
+// network in walking order, or nil. Bellman-Ford starts every side at
+// distance zero, so a side still improving after a pass per side lies on or
+// behind such a cycle. Sides are visited in room name order so the same farm
+// always cancels the same cycles.
+func (p *planner) negativeCycle(af *AntFarm) []side {
+	if af.Start == nil || af.End == nil {
+		return nil
+	}
+	names := make([]string, 0, len(af.Rooms))
+	for name := range af.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sides := make([]side, 0, 2*len(names))
+	dist := make(map[side]int, 2*len(names))
+	for _, name := range names {
+		for _, exit := range []bool{false, true} {
+			s := side{af.Rooms[name], exit}
+			sides = append(sides, s)
+			dist[s] = 0
+		}
+	}
+
+	prev := make(map[side]side)
+	var last side
+	for pass := 0; pass < len(sides); pass++ {
+		relaxed := false
+		for _, s := range sides {
+			p.residual(af, s, func(to side, cost int) {
+				if dist[s]+cost < dist[to] {
+					dist[to] = dist[s] + cost
+					prev[to] = s
+					relaxed, last = true, to
+				}
+			})
+		}
+		if !relaxed {
+			return nil
+		}
+	}
+
+	// Step back far enough to be on the cycle, then walk it once
+	for range sides {
+		last = prev[last]
+	}
+	cycle := []side{last}
+	for at := prev[last]; at != last; at = prev[at] {
+		cycle = append(cycle, at)
+	}
+	for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+		cycle[i], cycle[j] = cycle[j], cycle[i]
+	}
+	return cycle
+}
+
+// dropPath removes the whole flow path that runs through room
+func (p *planner) dropPath(af *AntFarm, room *models.Room) {
+	for at := room; at != af.Start; {
+		prev := p.predecessor(at)
+		if prev == nil {
+			break
+		}
+		delete(p.flow, arc{prev, at})
+		at = prev
+	}
+	for at := room; at != af.End; {
+		next := p.successor(at)
+		if next == nil {
+			break
+		}
+		delete(p.flow, arc{at, next})
+		at = next
+	}
+}
+
+// decompose turns the flow into paths sorted by length
+func (p *planner) decompose(af *AntFarm) []models.Path {
+	paths := make([]models.Path, 0)
+	if af.Start == nil {
+		return paths
+	}
+
+	for _, first := range af.Start.Connected {
+		if !p.flow[arc{af.Start, first}] {
+			continue
+		}
+		rooms := []*models.Room{af.Start, first}
+		for at := first; at != af.End; {
+			at = p.successor(at)
+			rooms = append(rooms, at)
+		}
+		paths = append(paths, models.Path{Rooms: rooms, Length: af.pathLength(rooms)})
+	}
+
+	sort.SliceStable(paths, func(i, j int) bool {
+		return paths[i].Length < paths[j].Length
+	})
+	return paths
+}
+
+// best returns the shortest paths worth using for the given number of ants
+func (p *planner) best(numAnts int) []models.Path {
+	lengths := make([]int, len(p.paths))
+	for i, path := range p.paths {
+		lengths[i] = path.Length
+	}
+	k, _ := bestPrefix(lengths, numAnts)
+	return p.paths[:k]
+}
+
+// bestPrefix picks how many of the sorted path lengths to use for numAnts ants
+// and returns that count with the turn the last ant arrives. Using k paths,
+// the ants finish once sum(turns - length + 1) covers every ant.
+func bestPrefix(lengths []int, numAnts int) (int, int) {
+	bestK, bestTurns := 0, 0
+	sum := 0
+	for k := 1; k <= len(lengths); k++ {
+		sum += lengths[k-1]
+		turns := (numAnts+sum+k-1)/k - 1
+		if turns < lengths[k-1] {
+			turns = lengths[k-1]
+		}
+		if bestK == 0 || turns < bestTurns {
+			bestK, bestTurns = k, turns
+		}
+	}
+	return bestK, bestTurns
+}
@@ -0,0 +1,129 @@
+package antfarm
+
+import (
+	"bufio"
+	"context"
+	"strings"
+	"testing"
+
+	"test/generator"
+	"test/models"
+)
+
+// parseFarm builds a farm from the text of an input file
+func parseFarm(t testing.TB, input string) *AntFarm {
+	t.Helper()
+	af := NewAntFarm()
+	state := &parserState{
+		scanner: bufio.NewScanner(strings.NewReader(input)),
+	}
+	if err := af.parseNumAnts(state); err != nil {
+		t.Fatalf("parseNumAnts() error = %v", err)
+	}
+	if err := af.parseRoomsAndLinks(state); err != nil {
+		t.Fatalf("parseRoomsAndLinks() error = %v", err)
+	}
+	if err := af.validate(); err != nil {
+		t.Fatalf("validate() error = %v", err)
+	}
+	af.initializeAnts()
+	return af
+}
+
+// benchFarms are the generated farms the benchmarks run over, smallest first.
+// Sizes stay within what the exhaustive path search finishes quickly.
+var benchFarms = []generator.Config{
+	{Preset: generator.FlowTen, Seed: 1},
+	{Preset: generator.Grid, Seed: 1},
+	{Preset: generator.FlowThousand, Seed: 1, Rooms: 120},
+	{Preset: generator.BigSuperposition, Seed: 1},
+}
+
+// generatedInput renders a generated farm as the text of an input file
+func generatedInput(tb testing.TB, cfg generator.Config) string {
+	tb.Helper()
+	f, err := generator.Generate(cfg)
+	if err != nil {
+		tb.Fatalf("Generate() error = %v", err)
+	}
+	var input strings.Builder
+	if _, err := f.WriteTo(&input); err != nil {
+		tb.Fatalf("WriteTo() error = %v", err)
+	}
+	return input.String()
+}
+
+// pathNames renders paths as "start-a-end" strings
+func pathNames(paths []models.Path) []string {
+	names := make([]string, len(paths))
+	for i, path := range paths {
+		rooms := make([]string, len(path.Rooms))
+		for j, room := range path.Rooms {
+			rooms[j] = room.Name
+		}
+		names[i] = strings.Join(rooms, "-")
+	}
+	return names
+}
+
+// crossFarm has a shortest path s-a-b-t that blocks both longer disjoint
+// paths s-a-d-t and s-c-b-t
+const crossFarm = `3
+##start
+s 0 0
+a 1 0
+b 2 0
+c 1 1
+d 2 1
+##end
+t 3 0
+s-a
+a-b
+b-t
+s-c
+c-b
+a-d
+d-t
+`
+
+func TestPlanner_augment(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	p := newPlanner(context.Background(), af)
+
+	got := strings.Join(pathNames(p.paths), " ")
+	want := "s-a-d-t s-c-b-t"
+	if got != want {
+		t.Errorf("newPlanner() paths = %v, want %v", got, want)
+	}
+
+	for room := range map[string]bool{"a": true, "b": true, "c": true, "d": true} {
+		if p.predecessor(af.Rooms[room]) == nil || p.successor(af.Rooms[room]) == nil {
+			t.Errorf("room %s is not on a planned path", room)
+		}
+	}
+}
+
+func TestBestPrefix(t *testing.T) {
+	testCases := []struct {
+		name      string
+		lengths   []int
+		numAnts   int
+		wantK     int
+		wantTurns int
+	}{
+		{"no paths", nil, 5, 0, 0},
+		{"single path", []int{3}, 4, 1, 6},
+		{"equal paths share ants", []int{2, 2}, 4, 2, 3},
+		{"long path not worth it", []int{1, 10}, 5, 1, 5},
+		{"long path pays off", []int{1, 3}, 10, 2, 6},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			k, turns := bestPrefix(tc.lengths, tc.numAnts)
+			if k != tc.wantK || turns != tc.wantTurns {
+				t.Errorf("bestPrefix() = %d, %d, want %d, %d", k, turns, tc.wantK, tc.wantTurns)
+			}
+		})
+	}
+}
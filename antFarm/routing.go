@@ -0,0 +1,78 @@
+package antfarm
+
+import (
+	"fmt"
+
+	"test/models"
+)
+
+// Routing is the rule that keeps ants from getting in each other's way, and
+// so decides what the paths of a run may share
+type Routing int
+
+const (
+	// VertexDisjoint lets each room other than the start and end hold one ant
+	// at a time, so paths share no rooms but the start and end
+	VertexDisjoint Routing = iota
+	// EdgeDisjoint lets rooms hold any number of ants while each tunnel still
+	// carries one ant a turn, so paths share no tunnels but may cross in rooms
+	EdgeDisjoint
+)
+
+// RoutingNames holds the name of each routing rule
+var RoutingNames = [...]string{"vertex", "edge"}
+
+func (r Routing) String() string {
+	if r < 0 || int(r) >= len(RoutingNames) {
+		return fmt.Sprintf("Routing(%d)", int(r))
+	}
+	return RoutingNames[r]
+}
+
+// LookupRouting returns the routing rule with the given name
+func LookupRouting(name string) (Routing, error) {
+	for r, n := range RoutingNames {
+		if n == name {
+			return Routing(r), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown routing %q", name)
+}
+
+// holds names what a path keeps to itself under the rule, in path order:
+// its middle rooms, those other than the start and end, or its tunnels
+func (r Routing) holds(path models.Path) []string {
+	if r == EdgeDisjoint {
+		names := make([]string, 0, len(path.Rooms))
+		for i := 1; i < len(path.Rooms); i++ {
+			l := newLink(path.Rooms[i-1], path.Rooms[i])
+			names = append(names, l.a.Name+"-"+l.b.Name)
+		}
+		return names
+	}
+	names := make([]string, 0, len(path.Rooms))
+	for i := 1; i < len(path.Rooms)-1; i++ {
+		names = append(names, path.Rooms[i].Name)
+	}
+	return names
+}
+
+// shares reports whether path holds anything under the rule that is in
+// taken. Unlike holds it stops at the first one found and allocates nothing.
+func (r Routing) shares(path models.Path, taken map[string]bool) bool {
+	if r == EdgeDisjoint {
+		for i := 1; i < len(path.Rooms); i++ {
+			l := newLink(path.Rooms[i-1], path.Rooms[i])
+			if taken[l.a.Name+"-"+l.b.Name] {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 1; i < len(path.Rooms)-1; i++ {
+		if taken[path.Rooms[i].Name] {
+			return true
+		}
+	}
+	return false
+}
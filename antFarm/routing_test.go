@@ -0,0 +1,151 @@
+package antfarm
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"test/models"
+)
+
+// hubFarm has two routes that both pass through m: vertex-disjoint routing
+// can only use one of them, edge-disjoint routing both
+const hubFarm = `4
+##start
+s 0 0
+a 1 0
+b 1 1
+m 2 0
+c 3 0
+d 3 1
+##end
+t 4 0
+s-a
+s-b
+a-m
+b-m
+m-c
+m-d
+c-t
+d-t
+`
+
+func TestLookupRouting(t *testing.T) {
+	for _, want := range []Routing{VertexDisjoint, EdgeDisjoint} {
+		got, err := LookupRouting(want.String())
+		if err != nil || got != want {
+			t.Errorf("LookupRouting(%q) = %v, %v, want %v", want.String(), got, err, want)
+		}
+	}
+	if _, err := LookupRouting("diagonal"); err == nil {
+		t.Error("LookupRouting(\"diagonal\") succeeded, want an error")
+	}
+	if _, err := NewAntFarm().Turns(WithRouting(Routing(len(RoutingNames)))); err == nil {
+		t.Error("WithRouting() accepted an unknown routing")
+	}
+	for _, r := range []Routing{-1, Routing(len(RoutingNames))} {
+		if got, want := r.String(), fmt.Sprintf("Routing(%d)", int(r)); got != want {
+			t.Errorf("Routing(%d).String() = %q, want %q", int(r), got, want)
+		}
+	}
+}
+
+func TestRouting_holds(t *testing.T) {
+	af := parseFarm(t, hubFarm)
+	path := func(names ...string) models.Path {
+		rooms := make([]*models.Room, len(names))
+		for i, name := range names {
+			rooms[i] = af.Rooms[name]
+		}
+		return models.Path{Rooms: rooms, Length: len(rooms) - 1}
+	}
+
+	testCases := []struct {
+		name         string
+		path         models.Path
+		vertex, edge []string
+	}{
+		{"through the hub", path("s", "a", "m", "c", "t"), []string{"a", "m", "c"}, []string{"a-s", "a-m", "c-m", "c-t"}},
+		{"direct", path("s", "t"), []string{}, []string{"s-t"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, r := range []Routing{VertexDisjoint, EdgeDisjoint} {
+				taken := map[string]bool{}
+				if r.shares(tc.path, taken) {
+					t.Errorf("%v.shares() with nothing taken = true", r)
+				}
+				for _, name := range r.holds(tc.path) {
+					taken[name] = true
+				}
+				if len(taken) > 0 && !r.shares(tc.path, taken) {
+					t.Errorf("%v.shares() with %q taken = false", r, r.holds(tc.path))
+				}
+			}
+			if got := VertexDisjoint.holds(tc.path); !reflect.DeepEqual(got, tc.vertex) {
+				t.Errorf("VertexDisjoint.holds() = %q, want %q", got, tc.vertex)
+			}
+			if got := EdgeDisjoint.holds(tc.path); !reflect.DeepEqual(got, tc.edge) {
+				t.Errorf("EdgeDisjoint.holds() = %q, want %q", got, tc.edge)
+			}
+		})
+	}
+}
+
+func TestWithRouting(t *testing.T) {
+	for _, finder := range PathFinders {
+		for _, simplify := range []bool{false, true} {
+			name := finder.Name()
+			opts := []Option{WithPathFinder(finder), WithRouting(EdgeDisjoint)}
+			if simplify {
+				name += " simplified"
+				opts = append(opts, WithSimplify())
+			}
+			t.Run(name, func(t *testing.T) {
+				af := parseFarm(t, hubFarm)
+				moves, err := af.SimulateMovement(opts...)
+				if err != nil {
+					t.Fatalf("SimulateMovement() error = %v", err)
+				}
+
+				got, err := af.Verify(strings.NewReader(moves), WithRouting(EdgeDisjoint))
+				if err != nil {
+					t.Fatalf("Verify() error = %v", err)
+				}
+				if want := (Verification{Turns: 5, Paths: 2}); got != want {
+					t.Errorf("Verify() = %+v, want %+v", got, want)
+				}
+				if _, err := af.Verify(strings.NewReader(moves)); err == nil || !strings.Contains(err.Error(), "both in m") {
+					t.Errorf("Verify() without edge routing error = %v, want ants both in m", err)
+				}
+
+				if turns, err := af.Turns(opts[:1]...); err != nil || turns != 7 {
+					t.Errorf("Turns() with vertex routing = %d, %v, want 7", turns, err)
+				}
+			})
+		}
+	}
+}
+
+func TestWithRouting_events(t *testing.T) {
+	af := parseFarm(t, hubFarm)
+	want, err := af.SimulateMovement(WithRouting(EdgeDisjoint))
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+
+	// An event after the last turn switches to the ant by ant simulation
+	// without changing the moves
+	if err := af.Schedule(models.Event{Turn: 20, Kind: models.BlockRoom, Room: "a"}); err != nil {
+		t.Fatalf("Schedule() error = %v", err)
+	}
+	got, err := af.SimulateMovement(WithRouting(EdgeDisjoint))
+	if err != nil {
+		t.Fatalf("SimulateMovement() with events error = %v", err)
+	}
+	if got != want {
+		t.Errorf("SimulateMovement() with events =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,128 @@
+package antfarm
+
+import (
+	"sort"
+
+	"test/models"
+)
+
+// simplified is a reduced copy of a farm for the path finders to search.
+// Rooms and tunnels on no path from start to end are pruned, and every chain
+// of two or more rooms with exactly two tunnels is collapsed into its first
+// room, weighted by the rooms it stands for. Paths from start to end in the
+// copy match those of the farm one for one.
+type simplified struct {
+	farm   *AntFarm
+	chains map[*models.Room][]*models.Room // collapsed room to the rooms it replaced, in order
+}
+
+// simplify builds the reduced copy of the farm
+func (af *AntFarm) simplify() *simplified {
+	s := &simplified{farm: af.copyFarm(), chains: make(map[*models.Room][]*models.Room)}
+	reduced := s.farm
+	reduced.Prune()
+	reduced.weights = make(map[*models.Room]int)
+
+	names := make([]string, 0, len(reduced.Rooms))
+	for name := range reduced.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	inChain := func(room *models.Room) bool {
+		return room != reduced.Start && room != reduced.End && len(room.Connected) == 2
+	}
+	for _, name := range names {
+		room, exists := reduced.Rooms[name]
+		if !exists || !inChain(room) || s.chains[room] != nil {
+			continue
+		}
+
+		// Walk out both ways to the rooms the chain hangs between
+		chain := []*models.Room{room}
+		prev, ends := room, [2]*models.Room{}
+		for side, next := range room.Connected {
+			for prev = room; inChain(next) && next != room; {
+				if side == 0 {
+					chain = append([]*models.Room{next}, chain...)
+				} else {
+					chain = append(chain, next)
+				}
+				prev, next = next, otherRoom(next, prev)
+			}
+			ends[side] = next
+		}
+		if len(chain) < 2 || ends[0] == ends[1] || ends[0] == room {
+			continue
+		}
+
+		// The first room of the chain takes the place of all of them
+		first, last := chain[0], chain[len(chain)-1]
+		replaceRoom(ends[1].Connected, last, first)
+		first.Connected = []*models.Room{ends[0], ends[1]}
+		for _, r := range chain[1:] {
+			delete(reduced.Rooms, r.Name)
+		}
+		reduced.weights[first] = len(chain) - 1
+
+		original := make([]*models.Room, len(chain))
+		for i, r := range chain {
+			original[i] = af.Rooms[r.Name]
+		}
+		s.chains[first] = original
+	}
+	return s
+}
+
+// expand maps paths through the reduced farm back onto the farm's own rooms,
+// keeping their order
+func (s *simplified) expand(af *AntFarm, paths []models.Path) []models.Path {
+	expanded := make([]models.Path, len(paths))
+	for i, path := range paths {
+		rooms := make([]*models.Room, 0, path.Length+1)
+		for j, room := range path.Rooms {
+			chain, collapsed := s.chains[room]
+			if !collapsed {
+				rooms = append(rooms, af.Rooms[room.Name])
+				continue
+			}
+			// A chain is recorded from one end; walk it backwards from the other
+			if !hasRoom(chain[0].Connected, af.Rooms[path.Rooms[j-1].Name]) {
+				for k := len(chain) - 1; k >= 0; k-- {
+					rooms = append(rooms, chain[k])
+				}
+				continue
+			}
+			rooms = append(rooms, chain...)
+		}
+		expanded[i] = models.Path{Rooms: rooms, Length: len(rooms) - 1}
+	}
+	return expanded
+}
+
+// pathLength returns the number of moves along rooms, counting the rooms a
+// simplified room stands for
+func (af *AntFarm) pathLength(rooms []*models.Room) int {
+	length := len(rooms) - 1
+	for _, room := range rooms {
+		length += af.weights[room]
+	}
+	return length
+}
+
+// otherRoom returns the neighbour of a two-tunnel room that is not from
+func otherRoom(room, from *models.Room) *models.Room {
+	if room.Connected[0] == from {
+		return room.Connected[1]
+	}
+	return room.Connected[0]
+}
+
+// replaceRoom swaps old for room in rooms, keeping its position
+func replaceRoom(rooms []*models.Room, old, room *models.Room) {
+	for i, r := range rooms {
+		if r == old {
+			rooms[i] = room
+		}
+	}
+}
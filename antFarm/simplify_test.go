@@ -0,0 +1,80 @@
+package antfarm
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// chainFarm routes s to t through a chain m-b-n, listed so the chain is
+// recorded from the end room, and through d, which has a dead end e
+const chainFarm = `4
+##start
+s 0 0
+m 1 0
+b 2 0
+n 3 0
+d 1 1
+e 1 2
+##end
+t 4 0
+s-m
+b-n
+m-b
+n-t
+s-d
+d-t
+d-e
+`
+
+func TestAntFarm_simplify(t *testing.T) {
+	af := parseFarm(t, chainFarm)
+	s := af.simplify()
+
+	rooms := make([]string, 0, len(s.farm.Rooms))
+	for name := range s.farm.Rooms {
+		rooms = append(rooms, name)
+	}
+	sort.Strings(rooms)
+	if want := []string{"d", "n", "s", "t"}; !reflect.DeepEqual(rooms, want) {
+		t.Errorf("simplify() rooms = %v, want %v", rooms, want)
+	}
+	if got := s.farm.weights[s.farm.Rooms["n"]]; got != 2 {
+		t.Errorf("simplify() weight of n = %d, want 2", got)
+	}
+	if len(af.Rooms) != 7 || len(af.Rooms["b"].Connected) != 2 {
+		t.Error("simplify() changed the farm")
+	}
+
+	paths := s.expand(af, s.farm.findAllPaths())
+	want := []string{"s-d-t", "s-m-b-n-t"}
+	if got := pathNames(paths); !reflect.DeepEqual(got, want) {
+		t.Errorf("expand() = %v, want %v", got, want)
+	}
+	for _, path := range paths {
+		if path.Length != len(path.Rooms)-1 || path.Rooms[0] != af.Start {
+			t.Errorf("expand() path %v is not on the farm's own rooms", pathNames(paths))
+		}
+	}
+}
+
+func TestWithSimplify(t *testing.T) {
+	for _, cfg := range benchFarms {
+		af := parseFarm(t, generatedInput(t, cfg))
+		for _, finder := range PathFinders {
+			t.Run(string(cfg.Preset)+"/"+finder.Name(), func(t *testing.T) {
+				want, err := af.SimulateMovement(WithPathFinder(finder))
+				if err != nil {
+					t.Fatalf("SimulateMovement() error = %v", err)
+				}
+				got, err := af.SimulateMovement(WithPathFinder(finder), WithSimplify())
+				if err != nil {
+					t.Fatalf("SimulateMovement(WithSimplify()) error = %v", err)
+				}
+				if got != want {
+					t.Errorf("SimulateMovement(WithSimplify()) moves differ from the full search")
+				}
+			})
+		}
+	}
+}
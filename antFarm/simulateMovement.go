@@ -1,109 +1,333 @@
 package antfarm
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"strconv"
 	"strings"
 
 	"test/models"
 )
 
-// simulateMovement simulates the movement of all ants using multiple paths
-func (af *AntFarm) SimulateMovement() (string, error) {
-	antPaths := af.assignAntsToPath()
-	if antPaths == nil {
-		return "", errors.New("ERROR: no valid path found between start and end")
+// simulation holds the state of a single run. The farm is only read, so
+// any number of simulations can share it.
+type simulation struct {
+	ctx       context.Context
+	bestSoFar bool
+	farm      *AntFarm
+	numAnts   int
+	finder    PathFinder
+	assigner  Assigner
+	simplify  bool
+	routing   Routing
+	ants      []*models.Ant // only created when events move ants one by one
+	turns     int
+	explain   func(candidates []models.Path, sel pathSelection) // shown the default search's candidates, when set
+}
+
+// SimulateMovement simulates the movement of all ants using multiple paths.
+// Every call starts over with fresh ants in the start room, so a farm can be
+// simulated repeatedly, and from several goroutines at once.
+func (af *AntFarm) SimulateMovement(opts ...Option) (string, error) {
+	var moves strings.Builder
+	if err := af.WriteMovement(&moves, opts...); err != nil {
+		return "", err
 	}
+	return moves.String(), nil
+}
 
-	if len(af.Ants) == 0 {
-		return "", errors.New("no ants available")
+// WriteMovement simulates like SimulateMovement but writes each turn to w as
+// soon as it is computed instead of holding the whole transcript in memory.
+// Nothing is written when the farm has no route for the ants.
+func (af *AntFarm) WriteMovement(w io.Writer, opts ...Option) error {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	sim, err := af.newSimulation(opts)
+	if err != nil {
+		return err
 	}
+	return sim.run(w)
+}
 
-	// Initialize ant positions
-	for ant := range antPaths {
-		path := antPaths[ant]
-		ant.Path = path.Rooms
-		ant.PathIndex = 0
-		ant.CurrentRoom = path.Rooms[0]
-		ant.HasReached = false
+// Turns simulates the farm and returns the number of turns the ants need
+func (af *AntFarm) Turns(opts ...Option) (int, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	sim, err := af.newSimulation(opts)
+	if err != nil {
+		return 0, err
+	}
+	if err := sim.run(io.Discard); err != nil {
+		return 0, err
 	}
+	return sim.turns, nil
+}
 
-	// Track room occupancy
-	occupiedRooms := make(map[*models.Room]*models.Ant)
-	allMoves := ""
+// Sweep returns the turns the ants need for every ant count from `from` to
+// `to`, in order. The farm is searched once, and each count is spread by the
+// run's assigner over the paths the run would use for it: the same paths
+// for every count unless the finder chooses them by the number of ants.
+// Events are replayed for every count.
+func (af *AntFarm) Sweep(from, to int, opts ...Option) ([]int, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
 
-	// Simulate movements
-	for {
-		moves := make([]string, 0)
-		allReached := true
-		move := 0
+	if from < 1 || to < from {
+		return nil, fmt.Errorf("invalid ant range %d..%d", from, to)
+	}
+	sim, err := af.newSimulation(append(opts, WithAnts(to)))
+	if err != nil {
+		return nil, err
+	}
+	pathsFor := sim.pathsByAnts()
 
-		// Clear non-start/end room occupancy at start of turn
-		for room := range occupiedRooms {
-			if !room.IsStart && !room.IsEnd {
-				occupiedRooms[room] = nil
-			}
+	turns := make([]int, 0, to-from+1)
+	for numAnts := from; numAnts <= to; numAnts++ {
+		if err := sim.interrupted(); err != nil {
+			return nil, err
+		}
+		paths, err := sim.checkPaths(pathsFor(numAnts))
+		if err != nil {
+			return nil, err
+		}
+		sol := sim.assigner.Assign(paths, numAnts)
+		if len(af.Events) == 0 {
+			turns = append(turns, sol.Turns)
+			continue
+		}
+		sim.numAnts, sim.turns = numAnts, 0
+		if err := sim.write(io.Discard, sol); err != nil {
+			return nil, err
 		}
+		turns = append(turns, sim.turns)
+	}
+	return turns, nil
+}
 
-		// Try to move each ant
-		for _, ant := range af.Ants {
-			move++
-			if ant == nil {
-				return "", errors.New("ant is nil")
-			}
+// WriteSolution writes the moves of a solution found for this farm, such as
+// one from SolveAnytime, as WriteMovement would. The ants are the ones the
+// solution counts, whatever the options say.
+func (af *AntFarm) WriteSolution(w io.Writer, sol models.Solution, opts ...Option) error {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
 
-			if len(ant.Path) == 0 {
-				return "", fmt.Errorf("ant %d has no valid path", ant.Id)
-			}
-			if ant.CurrentRoom == nil {
-				return "", fmt.Errorf("ant %d has no current room set", ant.Id)
-			}
+	sim, err := af.newSimulation(opts)
+	if err != nil {
+		return err
+	}
+	sim.numAnts = 0
+	for _, count := range sol.Counts {
+		sim.numAnts += count
+	}
+	return sim.write(w, sol)
+}
 
-			if ant.HasReached {
-				move = 0
-				continue
-			}
-			if len(ant.Path) == 2 && move >= 2 {
-				continue
-			}
+// newSimulation prepares a run for the farm
+func (af *AntFarm) newSimulation(opts []Option) (*simulation, error) {
+	cfg, err := af.newRunConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.simulation(af), nil
+}
 
-			allReached = false
+// simulation prepares a run of the settings on farm, which may be a copy of
+// the farm they were made for
+func (cfg runConfig) simulation(farm *AntFarm) *simulation {
+	return &simulation{
+		ctx:       cfg.ctx,
+		bestSoFar: cfg.bestSoFar,
+		farm:      farm,
+		numAnts:   cfg.numAnts,
+		finder:    cfg.finder,
+		assigner:  cfg.assigner,
+		simplify:  cfg.simplify,
+		routing:   cfg.routing,
+	}
+}
 
-			// Check if ant can move
-			if ant.PathIndex < len(ant.Path)-1 {
-				nextRoom := ant.Path[ant.PathIndex+1]
+// Solve plans a run without simulating it and returns the paths, the number
+// of ants on each and the predicted finishing turn. Events are not taken
+// into account.
+func (af *AntFarm) Solve(opts ...Option) (models.Solution, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
 
-				// Check if next room is available
-				if occupiedRooms[nextRoom] == nil || nextRoom.IsEnd {
-					// Move ant
-					if !ant.CurrentRoom.IsStart && !ant.CurrentRoom.IsEnd {
-						occupiedRooms[ant.CurrentRoom] = nil
-					}
+	sim, err := af.newSimulation(opts)
+	if err != nil {
+		return models.Solution{}, err
+	}
+	return sim.solve()
+}
 
-					ant.CurrentRoom = nextRoom
-					if !nextRoom.IsStart && !nextRoom.IsEnd {
-						occupiedRooms[nextRoom] = ant
-					}
+// solve finds the paths for the run and spreads the ants over them
+func (s *simulation) solve() (models.Solution, error) {
+	paths, err := s.findPaths()
+	if err != nil {
+		return models.Solution{}, err
+	}
+	if s.numAnts == 0 {
+		return models.Solution{}, errors.New("no ants available")
+	}
+	return s.assigner.Assign(paths, s.numAnts), nil
+}
 
-					ant.PathIndex++
-					moves = append(moves, fmt.Sprintf("L%d-%s", ant.Id, nextRoom.Name))
+// findPaths searches the paths for the run's ants
+func (s *simulation) findPaths() ([]models.Path, error) {
+	return s.checkPaths(s.pathsByAnts()(s.numAnts))
+}
 
-					if nextRoom.IsEnd {
-						ant.HasReached = true
-					}
-				}
-			}
+// pathsByAnts searches once and returns the paths the run uses for any
+// number of ants. They are the same for every count unless the finder, or
+// the planner, chooses them by the number of ants.
+func (s *simulation) pathsByAnts() func(numAnts int) []models.Path {
+	af := s.farm
+	switch {
+	case s.simplify && af.Start != nil && af.End != nil:
+		reduced := af.simplify()
+		var pathsFor func(numAnts int) []models.Path
+		if s.finder != nil {
+			pathsFor = pathsByAnts(s.ctx, s.finder, reduced.farm, s.routing)
+		} else {
+			pathsFor = samePaths(s.search(reduced.farm, reduced))
+		}
+		return func(numAnts int) []models.Path {
+			return reduced.expand(af, pathsFor(numAnts))
+		}
+	case s.finder != nil:
+		return pathsByAnts(s.ctx, s.finder, af, s.routing)
+	case af.planner != nil && s.routing == VertexDisjoint:
+		// Once the farm has been edited the planner's choice is used. It only
+		// keeps vertex-disjoint paths, so other routing always searches.
+		return af.planner.best
+	default:
+		return samePaths(s.search(af, nil))
+	}
+}
+
+// checkPaths fails a run whose search found no paths, or was cut short
+// unless the run settles for the best paths so far
+func (s *simulation) checkPaths(paths []models.Path) ([]models.Path, error) {
+	if err := s.ctx.Err(); err != nil && (!s.bestSoFar || len(paths) == 0) {
+		return nil, fmt.Errorf("path search stopped: %w", err)
+	}
+	if s.farm.Start == nil || s.farm.End == nil || len(paths) == 0 {
+		return nil, errors.New("ERROR: no valid path found between start and end")
+	}
+	return paths, nil
+}
+
+// search runs the default search on farm, the run's farm or reduced, its
+// simplification, and returns the best combination of the candidates. The
+// candidates and that combination are handed to explain when it is set,
+// mapped back onto the farm's own rooms.
+func (s *simulation) search(farm *AntFarm, reduced *simplified) []models.Path {
+	candidates := farm.candidatePaths(s.ctx)
+	sel := selectNonOverlapping(s.ctx, candidates, s.routing)
+	if s.explain != nil {
+		shown := candidates
+		if reduced != nil {
+			shown = reduced.expand(s.farm, candidates)
+		}
+		s.explain(shown, sel)
+	}
+	return keptPaths(candidates, sel)
+}
+
+// interrupted returns the context's error once the run has to stop. Runs
+// that settle for the best paths so far are only stopped while searching.
+func (s *simulation) interrupted() error {
+	if s.bestSoFar {
+		return nil
+	}
+	return s.ctx.Err()
+}
+
+// run moves the ants until all of them reach the end room, writing one line
+// per turn to w as soon as the turn is done
+func (s *simulation) run(w io.Writer) error {
+	sol, err := s.solve()
+	if err != nil {
+		return err
+	}
+	return s.write(w, sol)
+}
+
+// write moves the ants of a solution, one line per turn
+func (s *simulation) write(w io.Writer, sol models.Solution) error {
+	if len(s.farm.Events) > 0 {
+		return s.runWithEvents(w, sol)
+	}
+	return s.runPaths(w, sol)
+}
 
+// runPaths writes the moves of ants spread over paths the routing keeps
+// apart, so ants on different paths never meet where the rule forbids. The
+// k-th ant sent down a path leaves the start on turn k+1 and arrives on turn
+// length+k, so each turn follows from the number of ants per path alone and
+// no ant has to be tracked individually.
+func (s *simulation) runPaths(w io.Writer, sol models.Solution) error {
+	paths, counts := sol.Paths, sol.Counts
+	lengths := pathLengths(paths)
+
+	lastTurn, maxLength := 0, 0
+	for j, count := range counts {
+		if count > 0 {
+			lastTurn = max(lastTurn, lengths[j]+count-1)
+			maxLength = max(maxLength, lengths[j])
 		}
+	}
 
-		move = 0
-		if len(moves) > 0 {
-			allMoves += strings.Join(moves, " ") + "\n"
+	// Ants are numbered in assignment order: by arrival turn, then by path.
+	// arrivedBefore counts the ants that arrive before a given turn.
+	arrivedBefore := func(turn int) int {
+		n := 0
+		for j, length := range lengths {
+			n += min(max(turn-length, 0), counts[j])
+		}
+		return n
+	}
+
+	line := make([]byte, 0, 256)
+	for turn := 1; turn <= lastTurn; turn++ {
+		if err := s.interrupted(); err != nil {
+			return err
+		}
+		line = line[:0]
+		id := arrivedBefore(turn)
+
+		// Ants still on their way this turn arrive between now and maxLength-1
+		// turns from now; walking arrivals in order yields ascending ids.
+		for arrival := turn; arrival < turn+maxLength; arrival++ {
+			for j, length := range lengths {
+				k := arrival - length
+				if k < 0 || k >= counts[j] {
+					continue
+				}
+				id++
+				if k >= turn {
+					continue // still waiting in the start room
+				}
+
+				if len(line) > 0 {
+					line = append(line, ' ')
+				}
+				line = append(line, 'L')
+				line = strconv.AppendInt(line, int64(id), 10)
+				line = append(line, '-')
+				line = append(line, paths[j].Rooms[turn-k].Name...)
+			}
 		}
 
-		if allReached {
-			break
+		line = append(line, '\n')
+		if _, err := w.Write(line); err != nil {
+			return err
 		}
+		s.turns++
 	}
-	return allMoves, nil
+	return nil
 }
@@ -1,6 +1,8 @@
 package antfarm
 
 import (
+	"errors"
+	"io"
 	"strings"
 	"testing"
 
@@ -199,3 +201,200 @@ func TestAntFarm_SimulateMovement(t *testing.T) {
 		})
 	}
 }
+
+func TestAntFarm_SimulateMovement_rerun(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+
+	first, err := af.SimulateMovement()
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+	second, err := af.SimulateMovement()
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+	if first != second {
+		t.Errorf("second run differs from the first\nfirst =\n%v\nsecond =\n%v", first, second)
+	}
+
+	for _, ant := range af.Ants {
+		if ant.CurrentRoom != af.Start || ant.HasReached || ant.Path != nil {
+			t.Errorf("SimulateMovement() modified ant %d of the farm", ant.Id)
+		}
+	}
+
+	af.NumAnts = 1
+	got, err := af.SimulateMovement()
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+	if want := "L1-a\nL1-d\nL1-t\n"; got != want {
+		t.Errorf("SimulateMovement() with one ant got =\n%v\nwant =\n%v", got, want)
+	}
+}
+
+func TestAntFarm_SimulateMovement_concurrent(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	want, err := af.SimulateMovement()
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+
+	results := make(chan string)
+	for i := 0; i < 8; i++ {
+		go func() {
+			got, _ := af.SimulateMovement()
+			results <- got
+		}()
+	}
+	for i := 0; i < 8; i++ {
+		if got := <-results; got != want {
+			t.Errorf("concurrent run got =\n%v\nwant =\n%v", got, want)
+		}
+	}
+}
+
+func TestAntFarm_SimulateMovement_largeColony(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	af.MaxAnts = 1000000
+
+	turns, err := af.Turns(WithAnts(200000))
+	if err != nil {
+		t.Fatalf("Turns() error = %v", err)
+	}
+	// Two paths of length 3 share the ants evenly
+	if want := 100002; turns != want {
+		t.Errorf("Turns() = %d, want %d", turns, want)
+	}
+
+	if _, err := af.Turns(WithAnts(1000001)); err == nil {
+		t.Errorf("Turns() accepted more ants than MaxAnts")
+	}
+}
+
+// failingWriter accepts a fixed number of writes and then fails
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if w.writes == 0 {
+		return 0, errors.New("disk full")
+	}
+	w.writes--
+	return len(p), nil
+}
+
+func TestAntFarm_WriteMovement(t *testing.T) {
+	af := parseFarm(t, crossFarm)
+	want, err := af.SimulateMovement()
+	if err != nil {
+		t.Fatalf("SimulateMovement() error = %v", err)
+	}
+
+	var got strings.Builder
+	if err := af.WriteMovement(&got); err != nil {
+		t.Fatalf("WriteMovement() error = %v", err)
+	}
+	if got.String() != want {
+		t.Errorf("WriteMovement() wrote =\n%v\nwant =\n%v", got.String(), want)
+	}
+
+	if err := af.WriteMovement(&failingWriter{writes: 1}); err == nil {
+		t.Errorf("WriteMovement() ignored a write error")
+	}
+
+	var none strings.Builder
+	disconnected := parseFarm(t, "1\n##start\ns 0 0\n##end\nt 1 1\n")
+	if err := disconnected.WriteMovement(&none); err == nil || none.Len() != 0 {
+		t.Errorf("WriteMovement() on an unsolvable farm wrote %q, error %v", none.String(), err)
+	}
+}
+
+func BenchmarkAntFarm_SimulateMovement(b *testing.B) {
+	for _, cfg := range benchFarms {
+		b.Run(string(cfg.Preset), func(b *testing.B) {
+			af := parseFarm(b, generatedInput(b, cfg))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := af.WriteMovement(io.Discard); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// shortcutFarm's shortest route, s-a-b-t, cuts across both of the routes
+// many ants need, s-a-c-d-t and s-e-f-b-t
+const shortcutFarm = `12
+##start
+s 0 0
+a 1 0
+b 2 0
+c 1 1
+d 2 1
+e 0 2
+f 1 2
+##end
+t 3 0
+s-a
+a-b
+b-t
+a-c
+c-d
+d-t
+s-e
+e-f
+f-b
+`
+
+func TestAntFarm_Sweep(t *testing.T) {
+	testCases := []struct {
+		name   string
+		input  string
+		events []models.Event
+		opts   []Option
+	}{
+		{"without events", crossFarm, nil, nil},
+		{"with events", crossFarm, []models.Event{{Turn: 2, Kind: models.BlockRoom, Room: "d"}}, nil},
+		// These finders choose their paths by the number of ants
+		{"k-shortest", shortcutFarm, nil, []Option{WithPathFinder(KShortestDisjoint)}},
+		{"k-shortest simplified", shortcutFarm, nil, []Option{WithPathFinder(KShortestDisjoint), WithSimplify()}},
+		{"max-flow", shortcutFarm, nil, []Option{WithPathFinder(MaxFlow)}},
+		{"max-flow edge routing", shortcutFarm, nil, []Option{WithPathFinder(MaxFlow), WithRouting(EdgeDisjoint)}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, tc.input)
+			for _, event := range tc.events {
+				if err := af.Schedule(event); err != nil {
+					t.Fatalf("Schedule() error = %v", err)
+				}
+			}
+
+			got, err := af.Sweep(1, 12, tc.opts...)
+			if err != nil {
+				t.Fatalf("Sweep() error = %v", err)
+			}
+			if len(got) != 12 {
+				t.Fatalf("Sweep() returned %d counts, want 12", len(got))
+			}
+			for i, turns := range got {
+				want, err := af.Turns(append(tc.opts, WithAnts(i+1))...)
+				if err != nil {
+					t.Fatalf("Turns() error = %v", err)
+				}
+				if turns != want {
+					t.Errorf("Sweep() for %d ants = %d, want %d", i+1, turns, want)
+				}
+			}
+		})
+	}
+
+	if _, err := parseFarm(t, crossFarm).Sweep(5, 4); err == nil {
+		t.Error("Sweep(5, 4) succeeded, want an error")
+	}
+}
@@ -0,0 +1,83 @@
+package antfarm
+
+import (
+	"math"
+	"sort"
+
+	"test/models"
+)
+
+// Suggestion is a tunnel the farm lacks that would get the ants through sooner
+type Suggestion struct {
+	From, To *models.Room
+	Distance float64 // straight-line distance between the rooms
+	Turns    int     // turns the ants need with the tunnel
+	Saved    int     // turns saved compared to the farm as it is
+}
+
+// SuggestLinks tries every tunnel the farm lacks, re-planning the run with
+// each one added, and returns the topK that save the most turns. A positive
+// maxDistance only tries rooms at most that far apart. Ties go to the shorter
+// tunnel, then to room names in order.
+func (af *AntFarm) SuggestLinks(topK int, maxDistance float64, opts ...Option) ([]Suggestion, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	cfg, err := af.newRunConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	sol, err := cfg.simulation(af).solve()
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(af.Rooms))
+	for name := range af.Rooms {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	suggestions := make([]Suggestion, 0)
+	for i, name1 := range names {
+		for _, name2 := range names[i+1:] {
+			room1, room2 := af.Rooms[name1], af.Rooms[name2]
+			distance := math.Hypot(float64(room1.X-room2.X), float64(room1.Y-room2.Y))
+			if hasRoom(room1.Connected, room2) || maxDistance > 0 && distance > maxDistance {
+				continue
+			}
+
+			clone := af.copyFarm()
+			from, to := clone.Rooms[name1], clone.Rooms[name2]
+			from.Connected = append(from.Connected, to)
+			to.Connected = append(to.Connected, from)
+
+			// The clone has no planner, so the default search runs afresh
+			trialSol, err := cfg.simulation(clone).solve()
+			if ctxErr := cfg.ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			if err != nil || trialSol.Turns >= sol.Turns {
+				continue
+			}
+			suggestions = append(suggestions, Suggestion{
+				From:     room1,
+				To:       room2,
+				Distance: distance,
+				Turns:    trialSol.Turns,
+				Saved:    sol.Turns - trialSol.Turns,
+			})
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		if suggestions[i].Saved != suggestions[j].Saved {
+			return suggestions[i].Saved > suggestions[j].Saved
+		}
+		return suggestions[i].Distance < suggestions[j].Distance
+	})
+	if topK > 0 && len(suggestions) > topK {
+		suggestions = suggestions[:topK]
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,55 @@
+package antfarm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAntFarm_SuggestLinks(t *testing.T) {
+	type suggestion struct {
+		Tunnel string
+		Turns  int
+		Saved  int
+	}
+	testCases := []struct {
+		name        string
+		topK        int
+		maxDistance float64
+		want        []suggestion
+	}{
+		{
+			name: "every pair",
+			want: []suggestion{{"b-s", 3, 2}, {"c-s", 3, 2}, {"s-t", 3, 2}, {"a-t", 4, 1}},
+		},
+		{
+			name: "top two",
+			topK: 2,
+			want: []suggestion{{"b-s", 3, 2}, {"c-s", 3, 2}},
+		},
+		{
+			name:        "near rooms only",
+			maxDistance: 2,
+			want:        []suggestion{{"b-s", 3, 2}, {"a-t", 4, 1}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, funnelFarm)
+			got, err := af.SuggestLinks(tc.topK, tc.maxDistance)
+			if err != nil {
+				t.Fatalf("SuggestLinks() error = %v", err)
+			}
+			suggestions := make([]suggestion, len(got))
+			for i, s := range got {
+				suggestions[i] = suggestion{s.From.Name + "-" + s.To.Name, s.Turns, s.Saved}
+			}
+			if !reflect.DeepEqual(suggestions, tc.want) {
+				t.Errorf("SuggestLinks() = %v, want %v", suggestions, tc.want)
+			}
+			if got := len(af.Rooms["s"].Connected); got != 1 {
+				t.Errorf("SuggestLinks() changed the farm: s has %d links, want 1", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package antfarm
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"test/models"
+)
+
+// Verification summarises a transcript that Verify accepted
+type Verification struct {
+	Turns int
+	Paths int // distinct routes the ants took
+}
+
+// Verify replays a transcript of moves, one turn per line, and checks that it
+// follows the rules: every ant moves at most once a turn and only through a
+// tunnel from its current room, each tunnel carries one ant a turn, no room
+// other than the start and end ever holds two ants, and every ant finishes in
+// the end room. Under EdgeDisjoint routing, given in opts, rooms may hold any
+// number of ants. Lines starting with "#" are skipped. Events are not
+// replayed, so transcripts must come from the farm as parsed.
+func (af *AntFarm) Verify(r io.Reader, opts ...Option) (Verification, error) {
+	af.mu.RLock()
+	defer af.mu.RUnlock()
+
+	cfg, err := af.newRunConfig(opts)
+	if err != nil {
+		return Verification{}, err
+	}
+	if af.Start == nil || af.End == nil {
+		return Verification{}, errors.New("farm has no start or end room")
+	}
+
+	at := make([]*models.Room, cfg.numAnts+1)
+	routes := make([][]byte, cfg.numAnts+1)
+	for id := 1; id <= cfg.numAnts; id++ {
+		at[id] = af.Start
+	}
+	occupant := make(map[*models.Room]int)
+
+	type move struct {
+		id   int
+		room *models.Room
+	}
+	reader := bufio.NewReader(r)
+	turns := 0
+	for {
+		line, readErr := reader.ReadString('\n')
+		if readErr != nil && readErr != io.EOF {
+			return Verification{}, readErr
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if readErr == io.EOF && line == "" {
+			break
+		}
+		if err := cfg.ctx.Err(); err != nil {
+			return Verification{}, err
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		turns++
+
+		moved := make(map[int]bool)
+		tunnels := make(map[link]bool)
+		moves := make([]move, 0)
+		for _, token := range strings.Fields(line) {
+			idText, name, ok := strings.Cut(strings.TrimPrefix(token, "L"), "-")
+			id, err := strconv.Atoi(idText)
+			if !strings.HasPrefix(token, "L") || !ok || err != nil {
+				return Verification{}, fmt.Errorf("turn %d: malformed move %q", turns, token)
+			}
+			if id < 1 || id > cfg.numAnts {
+				return Verification{}, fmt.Errorf("turn %d: unknown ant L%d", turns, id)
+			}
+			room, exists := af.Rooms[name]
+			if !exists {
+				return Verification{}, fmt.Errorf("turn %d: L%d moves to unknown room %s", turns, id, name)
+			}
+			from := at[id]
+			switch {
+			case moved[id]:
+				return Verification{}, fmt.Errorf("turn %d: L%d moves twice", turns, id)
+			case from == af.End:
+				return Verification{}, fmt.Errorf("turn %d: L%d moves after reaching %s", turns, id, af.End.Name)
+			case !hasRoom(from.Connected, room):
+				return Verification{}, fmt.Errorf("turn %d: L%d has no tunnel from %s to %s", turns, id, from.Name, name)
+			case tunnels[newLink(from, room)]:
+				return Verification{}, fmt.Errorf("turn %d: tunnel %s-%s used twice", turns, from.Name, name)
+			}
+			moved[id] = true
+			tunnels[newLink(from, room)] = true
+			moves = append(moves, move{id, room})
+		}
+
+		// Ants may follow each other, so rooms are freed before any are entered
+		for _, m := range moves {
+			if occupant[at[m.id]] == m.id {
+				delete(occupant, at[m.id])
+			}
+		}
+		for _, m := range moves {
+			if cfg.routing == VertexDisjoint && m.room != af.Start && m.room != af.End {
+				if other, taken := occupant[m.room]; taken {
+					return Verification{}, fmt.Errorf("turn %d: L%d and L%d both in %s", turns, other, m.id, m.room.Name)
+				}
+				occupant[m.room] = m.id
+			}
+			at[m.id] = m.room
+			routes[m.id] = append(append(routes[m.id], m.room.Name...), '-')
+		}
+
+		if readErr == io.EOF {
+			break
+		}
+	}
+
+	paths := make(map[string]bool)
+	for id := 1; id <= cfg.numAnts; id++ {
+		if at[id] != af.End {
+			return Verification{}, fmt.Errorf("L%d ends in %s instead of %s", id, at[id].Name, af.End.Name)
+		}
+		paths[string(routes[id])] = true
+	}
+	return Verification{Turns: turns, Paths: len(paths)}, nil
+}
@@ -0,0 +1,81 @@
+package antfarm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAntFarm_Verify(t *testing.T) {
+	testCases := []struct {
+		name       string
+		transcript string
+		want       Verification
+		wantErr    string
+	}{
+		{
+			name:       "valid",
+			transcript: "L1-a L2-c\nL1-d L2-b L3-a\nL1-t L2-t L3-d\nL3-t\n",
+			want:       Verification{Turns: 4, Paths: 2},
+		},
+		{
+			name:       "comments skipped",
+			transcript: "# turn 1\nL1-a L2-c\nL1-d L2-b L3-a\nL1-t L2-t L3-d\nL3-t",
+			want:       Verification{Turns: 4, Paths: 2},
+		},
+		{
+			name:       "following ant enters a vacated room",
+			transcript: "L1-a\nL1-b L2-a\nL1-t L2-b L3-a\nL2-t L3-b\nL3-t\n",
+			want:       Verification{Turns: 5, Paths: 1},
+		},
+		{"malformed move", "L1a\n", Verification{}, "malformed move"},
+		{"unknown ant", "L4-a\n", Verification{}, "unknown ant L4"},
+		{"unknown room", "L1-z\n", Verification{}, "unknown room z"},
+		{"no tunnel", "L1-b\n", Verification{}, "no tunnel from s to b"},
+		{"moves twice", "L1-a L1-b\n", Verification{}, "L1 moves twice"},
+		{"tunnel used twice", "L1-a L2-a\n", Verification{}, "tunnel s-a used twice"},
+		{"room collision", "L1-a\nL2-c\nL1-b L2-b\n", Verification{}, "both in b"},
+		{"blocked by a waiting ant", "L1-a\nL1-b L2-c\nL2-b\n", Verification{}, "L1 and L2 both in b"},
+		{"ant left behind", "L1-a L2-c\nL1-d L2-b\nL1-t L2-t\n", Verification{}, "L3 ends in s"},
+		{"ant moves on from the end", "L1-a\nL1-d\nL1-t\nL1-d\n", Verification{}, "L1 moves after reaching t"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			af := parseFarm(t, crossFarm)
+			got, err := af.Verify(strings.NewReader(tc.transcript))
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("Verify() error = %v, want %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Verify() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAntFarm_Verify_pathFinders(t *testing.T) {
+	for _, cfg := range benchFarms[:3] {
+		af := parseFarm(t, generatedInput(t, cfg))
+		for _, finder := range PathFinders {
+			var moves strings.Builder
+			if err := af.WriteMovement(&moves, WithPathFinder(finder)); err != nil {
+				t.Fatalf("%s/%s: WriteMovement() error = %v", cfg.Preset, finder.Name(), err)
+			}
+			got, err := af.Verify(strings.NewReader(moves.String()))
+			if err != nil {
+				t.Errorf("%s/%s: Verify() error = %v", cfg.Preset, finder.Name(), err)
+				continue
+			}
+			turns, _ := af.Turns(WithPathFinder(finder))
+			if got.Turns != turns {
+				t.Errorf("%s/%s: Verify() counted %d turns, Turns() = %d", cfg.Preset, finder.Name(), got.Turns, turns)
+			}
+		}
+	}
+}
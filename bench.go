@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"text/tabwriter"
+	"time"
+
+	antfarm "test/antFarm"
+)
+
+// benchResult holds the measurements for one farm file
+type benchResult struct {
+	File   string        `json:"file"`
+	Time   time.Duration `json:"time_ns"`
+	Allocs uint64        `json:"allocs"`
+	Bytes  uint64        `json:"bytes"`
+	Turns  int           `json:"turns"`
+	Error  string        `json:"error,omitempty"`
+}
+
+// bench parses and solves every farm in a directory, reporting the wall time,
+// allocations and turns of each
+func bench(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	asJSON := flags.Bool("json", false, "print the results as JSON instead of a table")
+	maxAnts := flags.Int("max-ants", antfarm.DefaultMaxAnts, "largest number of ants accepted")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("Usage: go run . bench [--json] [--max-ants <n>] <directory>")
+	}
+	dir := flags.Arg(0)
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	results := make([]benchResult, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		results = append(results, benchFile(filepath.Join(dir, entry.Name()), *maxAnts))
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "file\ttime\tallocs\tbytes\tturns")
+	for _, r := range results {
+		if r.Error != "" {
+			fmt.Fprintf(w, "%s\t%v\t%d\t%d\t%s\n", filepath.Base(r.File), r.Time, r.Allocs, r.Bytes, r.Error)
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%v\t%d\t%d\t%d\n", filepath.Base(r.File), r.Time, r.Allocs, r.Bytes, r.Turns)
+	}
+	return w.Flush()
+}
+
+// benchFile measures parsing and solving one farm. A farm that fails is
+// reported with its error rather than stopping the run.
+func benchFile(filename string, maxAnts int) benchResult {
+	result := benchResult{File: filename}
+
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	farm := antfarm.NewAntFarm()
+	farm.MaxAnts = maxAnts
+	err := farm.ParseInput(filename)
+	if err == nil {
+		result.Turns, err = farm.Turns()
+	}
+
+	result.Time = time.Since(start)
+	runtime.ReadMemStats(&after)
+	result.Allocs = after.Mallocs - before.Mallocs
+	result.Bytes = after.TotalAlloc - before.TotalAlloc
+	if err != nil {
+		result.Error = err.Error()
+	}
+	return result
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+	"time"
+
+	antfarm "test/antFarm"
+)
+
+// strategy is one way of solving a farm that compare measures
+type strategy struct {
+	name string
+	opts []antfarm.Option
+}
+
+// strategies lists every pairing of a path finder with an assigner
+func strategies() []strategy {
+	list := make([]strategy, 0, len(antfarm.PathFinders)*len(antfarm.Assigners))
+	for _, finder := range antfarm.PathFinders {
+		for _, assigner := range antfarm.Assigners {
+			list = append(list, strategy{
+				name: finder.Name() + "/" + assigner.Name(),
+				opts: []antfarm.Option{antfarm.WithPathFinder(finder), antfarm.WithAssigner(assigner)},
+			})
+		}
+	}
+	return list
+}
+
+// compareResult is one strategy's verified outcome on one farm
+type compareResult struct {
+	strategy string
+	time     time.Duration
+	turns    int
+	paths    int
+	err      error
+}
+
+// compare runs every strategy on a farm, or on every farm in a directory,
+// verifies the moves and prints the results side by side. The winner of
+// each farm, fewest turns and then fastest, is marked with a star; errors
+// take the place of the mark.
+func compare(args []string) error {
+	flags := flag.NewFlagSet("compare", flag.ExitOnError)
+	maxAnts := flags.Int("max-ants", antfarm.DefaultMaxAnts, "largest number of ants accepted")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("Usage: go run . compare [--max-ants <n>] <file|directory>")
+	}
+	files, err := farmFiles(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "file\tstrategy\tturns\tpaths\ttime\tbest")
+	for _, filename := range files {
+		name := filepath.Base(filename)
+		farm := antfarm.NewAntFarm()
+		farm.MaxAnts = *maxAnts
+		if err := farm.ParseInput(filename); err != nil {
+			fmt.Fprintf(w, "%s\t-\t-\t-\t-\t%v\n", name, err)
+			continue
+		}
+
+		results := compareFarm(farm)
+		best := -1
+		for i, r := range results {
+			if r.err == nil && (best < 0 || r.turns < results[best].turns ||
+				r.turns == results[best].turns && r.time < results[best].time) {
+				best = i
+			}
+		}
+
+		for i, r := range results {
+			mark := ""
+			if i == best {
+				mark = "*"
+			}
+			if r.err != nil {
+				fmt.Fprintf(w, "%s\t%s\t-\t-\t%v\t%v\n", name, r.strategy, r.time, r.err)
+				continue
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%v\t%s\n", name, r.strategy, r.turns, r.paths, r.time, mark)
+		}
+	}
+	return w.Flush()
+}
+
+// compareFarm solves the farm with every strategy, verifies each result and
+// checks it took the turns the strategy predicted
+func compareFarm(farm *antfarm.AntFarm) []compareResult {
+	results := make([]compareResult, 0)
+	for _, s := range strategies() {
+		var moves bytes.Buffer
+		start := time.Now()
+		err := farm.WriteMovement(&moves, s.opts...)
+		r := compareResult{strategy: s.name, time: time.Since(start)}
+
+		if err == nil {
+			var v antfarm.Verification
+			if v, err = farm.Verify(&moves, s.opts...); err == nil {
+				r.turns, r.paths = v.Turns, v.Paths
+			} else {
+				err = fmt.Errorf("invalid moves: %w", err)
+			}
+		}
+		if err == nil {
+			if sol, solveErr := farm.Solve(s.opts...); solveErr != nil {
+				err = solveErr
+			} else if sol.Turns != r.turns {
+				err = fmt.Errorf("predicted %d turns, took %d", sol.Turns, r.turns)
+			}
+		}
+		r.err = err
+		results = append(results, r)
+	}
+	return results
+}
+
+// farmFiles returns path itself, or every file in it when it is a directory
+func farmFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
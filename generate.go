@@ -0,0 +1,52 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"test/generator"
+)
+
+// generate writes a random farm in the input file format
+func generate(args []string) error {
+	presets := make([]string, len(generator.Presets))
+	for i, preset := range generator.Presets {
+		presets[i] = string(preset)
+	}
+
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	preset := flags.String("preset", string(generator.Random), "layout to generate: "+strings.Join(presets, ", "))
+	seed := flags.Int64("seed", 1, "random seed; the same seed and flags give the same farm")
+	ants := flags.Int("ants", 0, "number of ants (0 uses the preset's default)")
+	rooms := flags.Int("rooms", 0, "approximate number of rooms (0 uses the preset's default)")
+	density := flags.Float64("density", 0, "extra links per room (0 uses the preset's default)")
+	outFile := flags.String("out", "", "write the farm to a file instead of stdout")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		return fmt.Errorf("Usage: go run . generate [--preset <name>] [--seed <n>] [--ants <n>] [--rooms <n>] [--density <x>] [--out <file>]")
+	}
+
+	farm, err := generator.Generate(generator.Config{
+		Preset:  generator.Preset(*preset),
+		Seed:    *seed,
+		Ants:    *ants,
+		Rooms:   *rooms,
+		Density: *density,
+	})
+	if err != nil {
+		return err
+	}
+
+	dest := os.Stdout
+	if *outFile != "" {
+		if dest, err = os.Create(*outFile); err != nil {
+			return err
+		}
+		defer dest.Close()
+	}
+	_, err = farm.WriteTo(dest)
+	return err
+}
@@ -0,0 +1,434 @@
+package generator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Preset names a farm layout
+type Preset string
+
+const (
+	// Random links rooms into a random spanning tree plus extra links
+	Random Preset = "random"
+	// Grid carves a maze through a grid of rooms
+	Grid Preset = "grid"
+	// FlowOne has a few disjoint corridors and a single ant
+	FlowOne Preset = "flow-one"
+	// FlowTen has more corridors, some cross-linked, for about ten ants
+	FlowTen Preset = "flow-ten"
+	// FlowThousand has many cross-linked corridors for about a thousand ants
+	FlowThousand Preset = "flow-thousand"
+	// BigSuperposition hides disjoint corridors behind short paths that
+	// cross two of them at once
+	BigSuperposition Preset = "big-superposition"
+	// GreedyTrap chains gadgets whose shortest path blocks the two longer
+	// disjoint paths beside it, and records the optimal turn count
+	GreedyTrap Preset = "greedy-trap"
+)
+
+// Presets lists every layout Generate understands
+var Presets = []Preset{Random, Grid, FlowOne, FlowTen, FlowThousand, BigSuperposition, GreedyTrap}
+
+// Config controls a generated farm. Zero values pick the preset's defaults.
+type Config struct {
+	Preset  Preset
+	Seed    int64
+	Ants    int
+	Rooms   int     // approximate number of rooms
+	Density float64 // extra links per room
+}
+
+// defaults holds the per-preset values used for zero config fields
+var defaults = map[Preset]Config{
+	Random:           {Ants: 20, Rooms: 50, Density: 0.5},
+	Grid:             {Ants: 20, Rooms: 100, Density: 0.1},
+	FlowOne:          {Ants: 1, Rooms: 40, Density: 0},
+	FlowTen:          {Ants: 10, Rooms: 100, Density: 0.05},
+	FlowThousand:     {Ants: 1000, Rooms: 400, Density: 0.05},
+	BigSuperposition: {Ants: 500, Rooms: 1000, Density: 0},
+	GreedyTrap:       {Ants: 100, Rooms: 40, Density: 0},
+}
+
+// Room is a generated room
+type Room struct {
+	Name string
+	X, Y int
+}
+
+// Link is a generated tunnel between two rooms
+type Link struct {
+	From, To string
+}
+
+// Farm is a generated farm ready to be written in the input file format
+type Farm struct {
+	Ants  int
+	Start string
+	End   string
+	Rooms []Room
+	Links []Link
+	// OptimalTurns is the fewest turns any solution can take, or zero when
+	// the layout does not know it
+	OptimalTurns int
+
+	linked map[Link]bool
+}
+
+// Generate builds a farm for cfg. The same config always yields the same farm.
+func Generate(cfg Config) (*Farm, error) {
+	if cfg.Preset == "" {
+		cfg.Preset = Random
+	}
+	def, ok := defaults[cfg.Preset]
+	if !ok {
+		return nil, fmt.Errorf("unknown preset %q", cfg.Preset)
+	}
+	if cfg.Ants == 0 {
+		cfg.Ants = def.Ants
+	}
+	if cfg.Rooms == 0 {
+		cfg.Rooms = def.Rooms
+	}
+	if cfg.Density == 0 {
+		cfg.Density = def.Density
+	}
+	if cfg.Ants < 0 || cfg.Rooms < 2 || cfg.Density < 0 {
+		return nil, fmt.Errorf("invalid config: %d ants, %d rooms, density %g", cfg.Ants, cfg.Rooms, cfg.Density)
+	}
+
+	f := &Farm{Ants: cfg.Ants, linked: make(map[Link]bool)}
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	switch cfg.Preset {
+	case Random:
+		f.random(rng, cfg)
+	case Grid:
+		f.grid(rng, cfg)
+	case FlowOne:
+		f.corridors(rng, cfg, 3)
+	case FlowTen:
+		f.corridors(rng, cfg, 6)
+	case FlowThousand:
+		f.corridors(rng, cfg, 16)
+	case BigSuperposition:
+		f.superposition(rng, cfg)
+	case GreedyTrap:
+		f.greedyTrap(rng, cfg)
+	}
+	return f, nil
+}
+
+// WriteTo writes the farm in the input file format
+func (f *Farm) WriteTo(w io.Writer) (int64, error) {
+	bw := bufio.NewWriter(w)
+	var n int64
+	write := func(format string, args ...any) {
+		written, _ := fmt.Fprintf(bw, format, args...)
+		n += int64(written)
+	}
+
+	write("%d\n", f.Ants)
+	if f.OptimalTurns > 0 {
+		write("#optimal turns %d\n", f.OptimalTurns)
+	}
+	for _, room := range f.Rooms {
+		switch room.Name {
+		case f.Start:
+			write("##start\n")
+		case f.End:
+			write("##end\n")
+		}
+		write("%s %d %d\n", room.Name, room.X, room.Y)
+	}
+	for _, link := range f.Links {
+		write("%s-%s\n", link.From, link.To)
+	}
+	return n, bw.Flush()
+}
+
+// addRoom appends a room and returns its name
+func (f *Farm) addRoom(name string, x, y int) string {
+	f.Rooms = append(f.Rooms, Room{Name: name, X: x, Y: y})
+	return name
+}
+
+// link connects two rooms unless they are the same or already linked
+func (f *Farm) link(from, to string) bool {
+	if from == to || f.linked[Link{from, to}] || f.linked[Link{to, from}] {
+		return false
+	}
+	f.linked[Link{from, to}] = true
+	f.Links = append(f.Links, Link{from, to})
+	return true
+}
+
+// addExtraLinks adds about count links between random rooms
+func (f *Farm) addExtraLinks(rng *rand.Rand, names []string, count int) {
+	for tries := 0; count > 0 && tries < count*10; tries++ {
+		if f.link(names[rng.Intn(len(names))], names[rng.Intn(len(names))]) {
+			count--
+		}
+	}
+}
+
+// random places rooms on shuffled grid cells and joins them with a random
+// spanning tree, so the end is always reachable, plus Density extra links
+// per room.
+func (f *Farm) random(rng *rand.Rand, cfg Config) {
+	side := int(math.Ceil(math.Sqrt(float64(cfg.Rooms))))
+	cells := rng.Perm(side * side)
+
+	names := make([]string, cfg.Rooms)
+	for i := range names {
+		name := fmt.Sprintf("r%d", i)
+		switch i {
+		case 0:
+			name = "start"
+		case cfg.Rooms - 1:
+			name = "end"
+		}
+		names[i] = f.addRoom(name, cells[i]%side*4, cells[i]/side*4)
+	}
+	f.Start, f.End = names[0], names[cfg.Rooms-1]
+
+	order := rng.Perm(cfg.Rooms)
+	for i := 1; i < len(order); i++ {
+		f.link(names[order[rng.Intn(i)]], names[order[i]])
+	}
+	f.addExtraLinks(rng, names, int(cfg.Density*float64(cfg.Rooms)))
+}
+
+// grid carves a maze through a square grid of rooms with a randomised depth
+// first walk, then knocks down Density extra walls per room. The start is
+// the top left corner and the end the bottom right one.
+func (f *Farm) grid(rng *rand.Rand, cfg Config) {
+	side := int(math.Ceil(math.Sqrt(float64(cfg.Rooms))))
+	if side < 2 {
+		side = 2
+	}
+	name := func(x, y int) string {
+		switch {
+		case x == 0 && y == 0:
+			return "start"
+		case x == side-1 && y == side-1:
+			return "end"
+		}
+		return fmt.Sprintf("g%d_%d", x, y)
+	}
+
+	names := make([]string, 0, side*side)
+	for y := 0; y < side; y++ {
+		for x := 0; x < side; x++ {
+			names = append(names, f.addRoom(name(x, y), x, y))
+		}
+	}
+	f.Start, f.End = "start", "end"
+
+	type cell struct{ x, y int }
+	steps := []cell{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	visited := map[cell]bool{{0, 0}: true}
+	stack := []cell{{0, 0}}
+	for len(stack) > 0 {
+		cur := stack[len(stack)-1]
+		next := make([]cell, 0, 4)
+		for _, step := range steps {
+			c := cell{cur.x + step.x, cur.y + step.y}
+			if c.x >= 0 && c.y >= 0 && c.x < side && c.y < side && !visited[c] {
+				next = append(next, c)
+			}
+		}
+		if len(next) == 0 {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		c := next[rng.Intn(len(next))]
+		visited[c] = true
+		f.link(name(cur.x, cur.y), name(c.x, c.y))
+		stack = append(stack, c)
+	}
+
+	walls := int(cfg.Density * float64(len(names)))
+	for tries := 0; walls > 0 && tries < walls*10; tries++ {
+		x, y := rng.Intn(side), rng.Intn(side)
+		step := steps[rng.Intn(len(steps))]
+		if x+step.x < 0 || y+step.y < 0 || x+step.x >= side || y+step.y >= side {
+			continue
+		}
+		if f.link(name(x, y), name(x+step.x, y+step.y)) {
+			walls--
+		}
+	}
+}
+
+// corridors lays out width disjoint corridors of random length between the
+// start and the end, then cross-links random corridor rooms at the given
+// density so that paths overlap.
+func (f *Farm) corridors(rng *rand.Rand, cfg Config, width int) {
+	if width > cfg.Rooms-2 {
+		width = max(cfg.Rooms-2, 1)
+	}
+	inner := max(cfg.Rooms-2, width)
+
+	f.Start = f.addRoom("start", 0, 0)
+	lanes := make([][]string, width)
+	names := make([]string, 0, inner)
+	remaining := inner
+	for c := 0; c < width; c++ {
+		// Give each corridor between half and one and a half times its share
+		share := remaining / (width - c)
+		length := share
+		if c < width-1 && share > 1 {
+			length = share/2 + rng.Intn(share+1)
+		}
+		length = max(1, min(length, remaining-(width-c-1)))
+		remaining -= length
+
+		for i := 0; i < length; i++ {
+			name := f.addRoom(fmt.Sprintf("c%d_%d", c, i), i+1, c*2)
+			lanes[c] = append(lanes[c], name)
+			names = append(names, name)
+		}
+	}
+
+	longest := 0
+	for _, lane := range lanes {
+		longest = max(longest, len(lane))
+	}
+	f.End = f.addRoom("end", longest+1, 0)
+
+	for _, lane := range lanes {
+		f.link(f.Start, lane[0])
+		for i := 1; i < len(lane); i++ {
+			f.link(lane[i-1], lane[i])
+		}
+		f.link(lane[len(lane)-1], f.End)
+	}
+	f.addExtraLinks(rng, names, int(cfg.Density*float64(len(names))))
+}
+
+// superposition builds corridors of equal length and adds a short bridge
+// from near the start of each corridor to near the end of the next one. The
+// bridges make the shortest paths, but each of them uses up two corridors.
+// Rooms left over become dead-end branches.
+func (f *Farm) superposition(rng *rand.Rand, cfg Config) {
+	width := max(2, int(math.Sqrt(float64(cfg.Rooms))/3))
+	length := max(4, (cfg.Rooms-2)*2/3/width)
+
+	f.Start = f.addRoom("start", 0, 0)
+	lanes := make([][]string, width)
+	names := make([]string, 0, cfg.Rooms)
+	for c := range lanes {
+		for i := 0; i < length; i++ {
+			name := f.addRoom(fmt.Sprintf("c%d_%d", c, i), i+1, c*3)
+			lanes[c] = append(lanes[c], name)
+			names = append(names, name)
+		}
+	}
+	f.End = f.addRoom("end", length+1, 0)
+
+	for _, lane := range lanes {
+		f.link(f.Start, lane[0])
+		for i := 1; i < len(lane); i++ {
+			f.link(lane[i-1], lane[i])
+		}
+		f.link(lane[len(lane)-1], f.End)
+	}
+
+	for c := 0; c+1 < width; c++ {
+		bridge := f.addRoom(fmt.Sprintf("b%d", c), length/2, c*3+1)
+		f.link(lanes[c][0], bridge)
+		f.link(bridge, lanes[c+1][length-1])
+	}
+
+	for i := len(f.Rooms); i < cfg.Rooms; i++ {
+		anchor := names[rng.Intn(len(names))]
+		name := f.addRoom(fmt.Sprintf("d%d", i), rng.Intn(length)+1, width*3+i)
+		f.link(anchor, name)
+		names = append(names, name)
+	}
+	f.addExtraLinks(rng, names, int(cfg.Density*float64(len(names))))
+}
+
+// maxTrapGadgets bounds the gadgets in a greedy trap, since finding the
+// optimal turn count tries every combination of them
+const maxTrapGadgets = 12
+
+// greedyTrap builds gadgets that share only the start and the end. Gadget g
+// has a short path start-a{g}-b{g}-end and two longer paths, one through a{g}
+// and one through b{g}. The short path is the first any shortest-first search
+// picks, yet it blocks both longer ones, so taking it costs a path in every
+// gadget that is not seeded with a long path. The optimal turn count is found
+// by trying, per gadget, the short path against the two long ones.
+func (f *Farm) greedyTrap(rng *rand.Rand, cfg Config) {
+	gadgets := min(max(2, (cfg.Rooms-2)/8), maxTrapGadgets)
+
+	f.Start, f.End = f.addRoom("start", 0, 0), "end"
+	shortLength, longLengths := 3, make([][2]int, gadgets)
+	longest := 0
+	for g := range longLengths {
+		y := g * 4
+		a := f.addRoom(fmt.Sprintf("a%d", g), 1, y)
+		b := f.addRoom(fmt.Sprintf("b%d", g), 2, y)
+		f.link(f.Start, a)
+		f.link(a, b)
+		f.link(b, f.End)
+
+		// start-a-x...-end and start-y...-b-end, both longer than start-a-b-end
+		prev := a
+		xs := 2 + rng.Intn(3)
+		for i := 0; i < xs; i++ {
+			room := f.addRoom(fmt.Sprintf("x%d_%d", g, i), i+2, y+1)
+			f.link(prev, room)
+			prev = room
+		}
+		f.link(prev, f.End)
+
+		prev = f.Start
+		ys := 2 + rng.Intn(3)
+		for i := 0; i < ys; i++ {
+			room := f.addRoom(fmt.Sprintf("y%d_%d", g, i), i+1, y+2)
+			f.link(prev, room)
+			prev = room
+		}
+		f.link(prev, b)
+
+		longLengths[g] = [2]int{xs + 2, ys + 2}
+		longest = max(longest, xs+1, ys)
+	}
+	f.addRoom(f.End, longest+2, 0)
+
+	for choice := 0; choice < 1<<gadgets; choice++ {
+		lengths := make([]int, 0, 2*gadgets)
+		for g, long := range longLengths {
+			if choice&(1<<g) != 0 {
+				lengths = append(lengths, long[0], long[1])
+			} else {
+				lengths = append(lengths, shortLength)
+			}
+		}
+		if turns := fewestTurns(lengths, cfg.Ants); f.OptimalTurns == 0 || turns < f.OptimalTurns {
+			f.OptimalTurns = turns
+		}
+	}
+}
+
+// fewestTurns returns the turns numAnts ants need over the best subset of
+// vertex-disjoint paths with the given lengths. The k shortest paths finish
+// once sum(turns - length + 1) covers every ant.
+func fewestTurns(lengths []int, numAnts int) int {
+	sort.Ints(lengths)
+	best, sum := 0, 0
+	for k := 1; k <= len(lengths); k++ {
+		sum += lengths[k-1]
+		turns := max((numAnts+sum+k-1)/k-1, lengths[k-1])
+		if best == 0 || turns < best {
+			best = turns
+		}
+	}
+	return best
+}
@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"bytes"
+	"testing"
+
+	antfarm "test/antFarm"
+)
+
+func TestGenerate(t *testing.T) {
+	for _, preset := range Presets {
+		t.Run(string(preset), func(t *testing.T) {
+			f, err := Generate(Config{Preset: preset, Seed: 7, Rooms: 30})
+			if err != nil {
+				t.Fatalf("Generate() error = %v", err)
+			}
+
+			var buf bytes.Buffer
+			if _, err := f.WriteTo(&buf); err != nil {
+				t.Fatalf("WriteTo() error = %v", err)
+			}
+
+			farm := antfarm.NewAntFarm()
+			if err := farm.ParseReader(&buf); err != nil {
+				t.Fatalf("generated farm does not parse: %v\n%s", err, buf.String())
+			}
+			if farm.NumAnts != defaults[preset].Ants {
+				t.Errorf("generated farm has %d ants, want %d", farm.NumAnts, defaults[preset].Ants)
+			}
+			if _, err := farm.Turns(); err != nil {
+				t.Errorf("generated farm cannot be solved: %v", err)
+			}
+
+			seen := make(map[[2]int]string)
+			for _, room := range f.Rooms {
+				at := [2]int{room.X, room.Y}
+				if other, taken := seen[at]; taken {
+					t.Errorf("rooms %s and %s share coordinates %v", other, room.Name, at)
+				}
+				seen[at] = room.Name
+			}
+		})
+	}
+}
+
+func TestGenerate_reproducible(t *testing.T) {
+	write := func(cfg Config) string {
+		f, err := Generate(cfg)
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		var buf bytes.Buffer
+		f.WriteTo(&buf)
+		return buf.String()
+	}
+
+	cfg := Config{Preset: Random, Seed: 42, Ants: 5, Rooms: 40, Density: 1}
+	if write(cfg) != write(cfg) {
+		t.Errorf("Generate() gave different farms for the same config")
+	}
+
+	other := cfg
+	other.Seed = 43
+	if write(cfg) == write(other) {
+		t.Errorf("Generate() ignored the seed")
+	}
+}
+
+func TestGenerate_invalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		cfg  Config
+	}{
+		{"unknown preset", Config{Preset: "spiral"}},
+		{"one room", Config{Rooms: 1}},
+		{"negative ants", Config{Ants: -1}},
+		{"negative density", Config{Density: -0.5}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := Generate(tc.cfg); err == nil {
+				t.Errorf("Generate() accepted %+v", tc.cfg)
+			}
+		})
+	}
+}
+
+func TestGenerate_greedyTrap(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		f, err := Generate(Config{Preset: GreedyTrap, Seed: seed})
+		if err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+		if f.OptimalTurns == 0 {
+			t.Fatalf("seed %d: OptimalTurns not set", seed)
+		}
+
+		var buf bytes.Buffer
+		f.WriteTo(&buf)
+		farm := antfarm.NewAntFarm()
+		if err := farm.ParseReader(&buf); err != nil {
+			t.Fatalf("seed %d: generated farm does not parse: %v", seed, err)
+		}
+		// The trap only works if greedy selection falls into it
+		turns, err := farm.Turns(antfarm.WithPathFinder(antfarm.DFSGreedy))
+		if err != nil {
+			t.Fatalf("seed %d: Turns() error = %v", seed, err)
+		}
+		if turns <= f.OptimalTurns {
+			t.Errorf("seed %d: dfs-greedy solved in %d turns, want worse than the optimal %d", seed, turns, f.OptimalTurns)
+		}
+		if turns, err := farm.Turns(antfarm.WithPathFinder(antfarm.KShortestDisjoint)); err != nil || turns != f.OptimalTurns {
+			t.Errorf("seed %d: k-shortest took %d turns (%v), want the optimal %d", seed, turns, err, f.OptimalTurns)
+		}
+	}
+}
+
+func TestFewestTurns(t *testing.T) {
+	testCases := []struct {
+		name    string
+		lengths []int
+		numAnts int
+		want    int
+	}{
+		{"one ant", []int{3, 4, 5}, 1, 3},
+		{"single path", []int{3}, 10, 12},
+		{"two equal paths", []int{4, 4}, 10, 8},
+		{"long path not worth it", []int{2, 10}, 5, 6},
+		{"unsorted", []int{5, 3}, 4, 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fewestTurns(tc.lengths, tc.numAnts); got != tc.want {
+				t.Errorf("fewestTurns(%v, %d) = %d, want %d", tc.lengths, tc.numAnts, got, tc.want)
+			}
+		})
+	}
+}
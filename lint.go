@@ -0,0 +1,36 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	antfarm "test/antFarm"
+)
+
+// lint reports rooms and tunnels that can never carry ants
+func lint(args []string) error {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	maxAnts := flags.Int("max-ants", antfarm.DefaultMaxAnts, "largest number of ants accepted")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("Usage: go run . lint [--max-ants <n>] <filename>")
+	}
+
+	farm := antfarm.NewAntFarm()
+	farm.MaxAnts = *maxAnts
+	if err := farm.ParseInput(flags.Arg(0)); err != nil {
+		return err
+	}
+
+	findings := farm.Lint()
+	if len(findings) == 0 {
+		fmt.Println("no problems found")
+		return nil
+	}
+	for _, finding := range findings {
+		fmt.Println(finding)
+	}
+	return nil
+}
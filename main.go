@@ -1,33 +1,285 @@
 package main
 
 import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	antfarm "test/antFarm"
+	"test/models"
 )
 
+// commands maps subcommand names to their entry points. Without a known
+// subcommand the arguments are a farm to solve.
+var commands = map[string]func(args []string) error{
+	"analyze":  analyze,
+	"bench":    bench,
+	"compare":  compare,
+	"generate": generate,
+	"lint":     lint,
+	"suggest":  suggest,
+}
+
 func main() {
-	if len(os.Args) != 2 {
-		log.Fatalln("Usage: go run . <filename>")
+	run := solve
+	args := os.Args[1:]
+	if len(args) > 0 {
+		if command, ok := commands[args[0]]; ok {
+			run, args = command, args[1:]
+		}
+	}
+	if err := run(args); err != nil {
+		log.Fatalln(err)
+	}
+}
+
+// solve simulates the farm file named in args
+func solve(args []string) error {
+	flags := flag.NewFlagSet("solve", flag.ExitOnError)
+	eventsFile := flags.String("events", "", "file of events to apply during the simulation, e.g. \"5 block h\"")
+	ants := flags.String("ants", "", "number of ants to simulate instead of the file's, or a range such as 1..500 to sweep")
+	maxAnts := flags.Int("max-ants", antfarm.DefaultMaxAnts, "largest number of ants accepted")
+	outFile := flags.String("out", "", "write the output to a file instead of stdout")
+	algo := flags.String("algo", antfarm.PathFinders[0].Name(), "path finder to use: "+pathFinderNames())
+	explain := flags.Bool("explain", false, "print how the paths and ant counts were chosen to stderr")
+	assign := flags.String("assign", antfarm.Assigners[0].Name(), "ant assigner to use: "+assignerNames())
+	routing := flags.String("routing", antfarm.VertexDisjoint.String(), "what paths may not share: vertex for rooms, edge for tunnels with rooms holding any number of ants")
+	prune := flags.Bool("prune", false, "remove the rooms and tunnels lint reports before solving")
+	simplify := flags.Bool("simplify", false, "search a reduced farm with dead ends dropped and corridors collapsed")
+	timeout := flags.Duration("timeout", 0, "give up solving after this long, e.g. 30s (0 waits for ever)")
+	bestSoFar := flags.Bool("best-so-far", false, "when --timeout runs out, use the best paths found by then instead of failing")
+	workers := flags.Int("workers", 0, "most goroutines the path search may use (0 uses one per CPU)")
+	maxStretch := flags.Float64("max-stretch", 0, "skip paths longer than this multiple of the shortest, e.g. 1.5 (0 keeps all)")
+	maxPaths := flags.Int("max-paths", 0, "stop the path search after this many paths (0 finds all)")
+	anytime := flags.Bool("anytime", false, "refine the solution until --timeout runs out, reporting each improvement to stderr")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("Usage: go run . [--events <file>] [--ants <n>|<from>..<to>] [--max-ants <n>] [--workers <n>] [--max-stretch <x>] [--max-paths <n>] [--out <file>] [--algo <name>] [--assign <name>] [--routing vertex|edge] [--explain] [--prune] [--simplify] [--timeout <d> [--best-so-far|--anytime]] <filename>\n       go run . generate|bench|compare|analyze|suggest|lint [flags]")
+	}
+	filename := flags.Arg(0)
+
+	ctx := context.Background()
+	if *timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *timeout)
+		defer cancel()
 	}
 
 	farm := antfarm.NewAntFarm()
-	if err := farm.ParseInput(os.Args[1]); err != nil {
-		log.Fatalln(err)
+	farm.MaxAnts = *maxAnts
+	farm.Workers = *workers
+	farm.Limits = antfarm.SearchLimits{MaxStretch: *maxStretch, MaxPaths: *maxPaths}
+	if err := farm.ParseInputContext(ctx, filename); err != nil {
+		return timedOut(err, *timeout)
+	}
+
+	// Pruning first means events naming a pruned room are reported rather
+	// than dropped with it
+	if *prune {
+		farm.Prune()
+	}
+
+	if *eventsFile != "" {
+		if err := farm.ParseEvents(*eventsFile); err != nil {
+			return err
+		}
+	}
+
+	opts := []antfarm.Option{antfarm.WithContext(ctx)}
+	if *bestSoFar {
+		opts = append(opts, antfarm.WithBestSoFar())
+	}
+	if *simplify {
+		opts = append(opts, antfarm.WithSimplify())
+	}
+	if *algo != antfarm.PathFinders[0].Name() {
+		finder, err := antfarm.LookupPathFinder(*algo)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, antfarm.WithPathFinder(finder))
+	}
+	if *assign != antfarm.Assigners[0].Name() {
+		assigner, err := antfarm.LookupAssigner(*assign)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, antfarm.WithAssigner(assigner))
+	}
+	if *routing != antfarm.VertexDisjoint.String() {
+		r, err := antfarm.LookupRouting(*routing)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, antfarm.WithRouting(r))
+	}
+	if *ants != "" {
+		from, to, err := parseAntRange(*ants)
+		if err != nil {
+			return err
+		}
+		if from != to {
+			return timedOut(sweep(farm, from, to, opts), *timeout)
+		}
+		opts = append(opts, antfarm.WithAnts(from))
+	}
+
+	var explained *models.Solution
+	if *explain {
+		sol, err := farm.Explain(os.Stderr, opts...)
+		if err != nil {
+			return timedOut(err, *timeout)
+		}
+		explained = &sol
 	}
 
-	moves, err := farm.SimulateMovement()
+	input, err := os.ReadFile(filename)
 	if err != nil {
-		log.Fatalln(err)
+		return err
+	}
+
+	dest := os.Stdout
+	if *outFile != "" {
+		if dest, err = os.Create(*outFile); err != nil {
+			return err
+		}
+		defer dest.Close()
 	}
 
-	input, err := os.ReadFile(os.Args[1])
+	out := bufio.NewWriter(dest)
+	moves := &headerWriter{w: out, header: []byte(string(input) + "\n\n")}
+	if *anytime {
+		sol, err := solveAnytime(farm, opts)
+		if err != nil {
+			return err
+		}
+		// The search ran until the deadline, which has passed by now, so the
+		// moves are written with the run's settings but without its context
+		if err := farm.WriteSolution(moves, sol, append(opts, antfarm.WithContext(context.Background()))...); err != nil {
+			return err
+		}
+		return out.Flush()
+	}
+	if explained != nil {
+		// The explanation has found the paths already
+		err = farm.WriteSolution(moves, *explained, opts...)
+	} else {
+		err = farm.WriteMovement(moves, opts...)
+	}
 	if err != nil {
-		log.Fatalln(err)
+		return timedOut(err, *timeout)
 	}
+	return out.Flush()
+}
 
-	fmt.Println(string(input) + "\n")
-	fmt.Print(moves)
+// solveAnytime reads the anytime solver's solutions until it stops, logging
+// each one to stderr, and returns the last and best of them
+func solveAnytime(farm *antfarm.AntFarm, opts []antfarm.Option) (models.Solution, error) {
+	solutions, err := farm.SolveAnytime(opts...)
+	if err != nil {
+		return models.Solution{}, err
+	}
+
+	started := time.Now()
+	var best models.Solution
+	for sol := range solutions {
+		best = sol
+		paths := 0
+		for _, count := range sol.Counts {
+			if count > 0 {
+				paths++
+			}
+		}
+		fmt.Fprintf(os.Stderr, "%s: %d turns (paths used: %d)\n", time.Since(started).Round(time.Millisecond), sol.Turns, paths)
+	}
+	if best.Turns == 0 {
+		return models.Solution{}, errors.New("ERROR: no solution found in time")
+	}
+	return best, nil
+}
+
+// timedOut explains an error caused by the --timeout running out
+func timedOut(err error, timeout time.Duration) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("ERROR: no solution within %s, try a longer --timeout or --best-so-far", timeout)
+	}
+	return err
+}
+
+// headerWriter writes header ahead of the first bytes passed through it, so
+// the input is only echoed once the simulation has something to show
+type headerWriter struct {
+	w      io.Writer
+	header []byte
+}
+
+func (h *headerWriter) Write(p []byte) (int, error) {
+	if h.header != nil {
+		if _, err := h.w.Write(h.header); err != nil {
+			return 0, err
+		}
+		h.header = nil
+	}
+	return h.w.Write(p)
+}
+
+// parseAntRange reads an --ants value, either "n" or "from..to"
+func parseAntRange(value string) (int, int, error) {
+	fromText, toText, isRange := strings.Cut(value, "..")
+	from, err := strconv.Atoi(fromText)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid --ants value %q", value)
+	}
+	if !isRange {
+		return from, from, nil
+	}
+
+	to, err := strconv.Atoi(toText)
+	if err != nil || to < from {
+		return 0, 0, fmt.Errorf("invalid --ants range %q", value)
+	}
+	return from, to, nil
+}
+
+// pathFinderNames lists the names accepted by --algo
+func pathFinderNames() string {
+	names := make([]string, len(antfarm.PathFinders))
+	for i, finder := range antfarm.PathFinders {
+		names[i] = finder.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// assignerNames lists the names accepted by --assign
+func assignerNames() string {
+	names := make([]string, len(antfarm.Assigners))
+	for i, assigner := range antfarm.Assigners {
+		names[i] = assigner.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// sweep prints the number of turns needed for every ant count in [from, to]
+func sweep(farm *antfarm.AntFarm, from, to int, opts []antfarm.Option) error {
+	turns, err := farm.Sweep(from, to, opts...)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "ants\tturns\t")
+	for i, t := range turns {
+		fmt.Fprintf(w, "%d\t%d\t\n", from+i, t)
+	}
+	return w.Flush()
 }
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	antfarm "test/antFarm"
+	"test/generator"
+)
+
+// hubFarm has two routes that both pass through m, so only edge routing
+// can use both of them
+const hubFarm = `4
+##start
+s 0 0
+a 1 0
+b 1 1
+m 2 0
+c 3 0
+d 3 1
+##end
+t 4 0
+s-a
+s-b
+a-m
+b-m
+m-c
+m-d
+c-t
+d-t
+`
+
+func TestSolve_anytime(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	var large strings.Builder
+	farm, err := generator.Generate(generator.Config{Preset: generator.FlowThousand, Seed: 3, Rooms: 400, Density: 0.3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := farm.WriteTo(&large); err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name   string
+		farm   string
+		events string
+		args   []string
+		want   antfarm.Verification
+		opts   []antfarm.Option
+	}{
+		{
+			// An event after the last turn makes the solution play out ant
+			// by ant
+			name:   "edge routing with events",
+			farm:   hubFarm,
+			events: "20 block c\n",
+			args:   []string{"--timeout", "500ms", "--routing", "edge"},
+			want:   antfarm.Verification{Turns: 5, Paths: 2},
+			opts:   []antfarm.Option{antfarm.WithRouting(antfarm.EdgeDisjoint)},
+		},
+		{
+			// The anytime search runs until the deadline, which has passed by
+			// the time the moves are written
+			name: "deadline reached",
+			farm: large.String(),
+			args: []string{"--timeout", "300ms"},
+			want: antfarm.Verification{Turns: 71, Paths: 16},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			farmFile := write(tc.name+".txt", tc.farm)
+			outFile := filepath.Join(dir, tc.name+".out")
+			args := append([]string{"--anytime", "--out", outFile}, tc.args...)
+			if tc.events != "" {
+				args = append(args, "--events", write(tc.name+".events", tc.events))
+			}
+			if err := solve(append(args, farmFile)); err != nil {
+				t.Fatalf("solve() error = %v", err)
+			}
+
+			out, err := os.ReadFile(outFile)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, moves, found := strings.Cut(string(out), "\n\n\n")
+			if !found {
+				t.Fatalf("solve() output has no moves after the farm:\n%s", out)
+			}
+
+			farm := antfarm.NewAntFarm()
+			if err := farm.ParseInput(farmFile); err != nil {
+				t.Fatalf("ParseInput() error = %v", err)
+			}
+			got, err := farm.Verify(strings.NewReader(moves), tc.opts...)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("Verify() = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
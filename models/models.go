@@ -30,6 +30,111 @@ type Path struct {
 	InUse  bool
 }
 
+// Dispatch records the path each ant takes, by ant id
+type Dispatch struct {
+	Paths  []Path
+	PathOf []int // PathOf[id-1] is the index in Paths of ant id's path
+}
+
+// Solution is a plan for a run: the paths used, how many ants take each of
+// them and the turn the last ant arrives on
+type Solution struct {
+	Paths  []Path
+	Counts []int // ants sent down each path
+	Turns  int
+	Moves  int // tunnels crossed by all the ants together
+}
+
+// Dispatch lists the path of every ant in the solution, in id order. Ants
+// are numbered by the turn they arrive, ties going to the lower path index,
+// which is the order their moves are printed in, so the same solution always
+// gives the same dispatch.
+func (s Solution) Dispatch() Dispatch {
+	first, last := 0, 0
+	total := 0
+	for j, count := range s.Counts {
+		if count > 0 {
+			length := s.Paths[j].Length
+			if total == 0 || length < first {
+				first = length
+			}
+			last = max(last, length+count-1)
+			total += count
+		}
+	}
+
+	pathOf := make([]int, 0, total)
+	for turn := first; turn <= last; turn++ {
+		for j, count := range s.Counts {
+			if k := turn - s.Paths[j].Length; k >= 0 && k < count {
+				pathOf = append(pathOf, j)
+			}
+		}
+	}
+	return Dispatch{Paths: s.Paths, PathOf: pathOf}
+}
+
 func (e *ParseError) Error() string {
 	return fmt.Sprintf("ERROR: invalid data format, %s", e.Message)
 }
+
+// Errors reported for invalid farm definitions, both by the input parser and
+// by the builder. Callers can match them with errors.Is.
+var (
+	ErrEmptyFile          = &ParseError{Message: "empty file"}
+	ErrInvalidNumAnts     = &ParseError{Message: "invalid number of ants"}
+	ErrNonPositiveAnts    = &ParseError{Message: "number of ants must be positive"}
+	ErrTooManyAnts        = &ParseError{Message: "number of ants exceeds maximum limit"}
+	ErrInvalidRoomFormat  = &ParseError{Message: "invalid room format"}
+	ErrInvalidRoomName    = &ParseError{Message: "invalid room name"}
+	ErrInvalidCoordinates = &ParseError{Message: "invalid room coordinates"}
+	ErrDuplicateRoom      = &ParseError{Message: "duplicate room name"}
+	ErrMultipleStart      = &ParseError{Message: "multiple start rooms defined"}
+	ErrMultipleEnd        = &ParseError{Message: "multiple end rooms defined"}
+	ErrInvalidLink        = &ParseError{Message: "invalid link format"}
+	ErrUnknownRoom        = &ParseError{Message: "link references nonexistent room"}
+	ErrDuplicateLink      = &ParseError{Message: "duplicate link"}
+	ErrNoStart            = &ParseError{Message: "no start room found"}
+	ErrNoEnd              = &ParseError{Message: "no end room found"}
+)
+
+// EventKind identifies what a scheduled event does to the farm
+type EventKind int
+
+const (
+	BlockRoom EventKind = iota
+	UnblockRoom
+	CloseLink
+	OpenLink
+)
+
+// EventKindNames holds the keyword used for each event kind in event files
+var EventKindNames = [...]string{
+	BlockRoom:   "block",
+	UnblockRoom: "unblock",
+	CloseLink:   "close",
+	OpenLink:    "open",
+}
+
+func (k EventKind) String() string {
+	if k < 0 || int(k) >= len(EventKindNames) {
+		return fmt.Sprintf("EventKind(%d)", int(k))
+	}
+	return EventKindNames[k]
+}
+
+// Event changes the farm at the start of the given turn, before any ant moves.
+// Room events use Room only; link events connect Room and To.
+type Event struct {
+	Turn int
+	Kind EventKind
+	Room string
+	To   string
+}
+
+func (e Event) String() string {
+	if e.Kind == CloseLink || e.Kind == OpenLink {
+		return fmt.Sprintf("turn %d: %s %s-%s", e.Turn, e.Kind, e.Room, e.To)
+	}
+	return fmt.Sprintf("turn %d: %s %s", e.Turn, e.Kind, e.Room)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	antfarm "test/antFarm"
+)
+
+// suggest lists the new tunnels that would cut the most turns
+func suggest(args []string) error {
+	flags := flag.NewFlagSet("suggest", flag.ExitOnError)
+	top := flags.Int("top", 5, "number of tunnels to suggest")
+	maxDist := flags.Float64("max-dist", 0, "only try rooms at most this far apart (0 tries every pair)")
+	maxAnts := flags.Int("max-ants", antfarm.DefaultMaxAnts, "largest number of ants accepted")
+	algo := flags.String("algo", antfarm.PathFinders[0].Name(), "path finder to plan with: "+pathFinderNames())
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("Usage: go run . suggest [--top <n>] [--max-dist <d>] [--max-ants <n>] [--algo <name>] <filename>")
+	}
+
+	farm := antfarm.NewAntFarm()
+	farm.MaxAnts = *maxAnts
+	if err := farm.ParseInput(flags.Arg(0)); err != nil {
+		return err
+	}
+	finder, err := antfarm.LookupPathFinder(*algo)
+	if err != nil {
+		return err
+	}
+
+	turns, err := farm.Turns(antfarm.WithPathFinder(finder))
+	if err != nil {
+		return err
+	}
+	suggestions, err := farm.SuggestLinks(*top, *maxDist, antfarm.WithPathFinder(finder))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("turns for %d ants (%s): %d\n", farm.NumAnts, finder.Name(), turns)
+	if len(suggestions) == 0 {
+		fmt.Println("no single new tunnel saves a turn")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "tunnel\tdistance\tturns\tsaved")
+	for _, s := range suggestions {
+		fmt.Fprintf(w, "%s-%s\t%.1f\t%d\t%d\n", s.From.Name, s.To.Name, s.Distance, s.Turns, s.Saved)
+	}
+	return w.Flush()
+}